@@ -0,0 +1,136 @@
+package argo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BrowsedWorkflow is a row of the workflow browser.
+type BrowsedWorkflow struct {
+	Workflow
+}
+
+// Description sums up the run: phase, start, duration and labels.
+func (w BrowsedWorkflow) Description() string {
+	parts := []string{w.Status.Phase}
+	if !w.Status.StartedAt.IsZero() {
+		parts = append(parts, w.Status.StartedAt.Local().Format("2006-01-02 15:04"), FormatDuration(w.Duration()))
+	}
+	if labels := UserLabels(w.Metadata.Labels); labels != "" {
+		parts = append(parts, labels)
+	}
+	if w.Archived {
+		parts = append(parts, "archived")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// PhaseIcon is the symbol of a workflow or node phase.
+func PhaseIcon(phase string) string {
+	switch phase {
+	case "Succeeded":
+		return "✔"
+	case "Failed", "Error":
+		return "✖"
+	case "Running":
+		return "⟳"
+	case "Pending":
+		return "…"
+	case "Skipped", "Omitted":
+		return "↷"
+	default:
+		return "•"
+	}
+}
+
+// FormatDuration renders a run time compactly, e.g. 3m07s.
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return d.Round(time.Second).String()
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// UserLabels joins the labels set by users, hiding Argo's bookkeeping ones.
+func UserLabels(labels map[string]string) string {
+	var pairs []string
+	for k, v := range labels {
+		if strings.HasPrefix(k, "workflows.argoproj.io/") {
+			continue
+		}
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// BrowseWorkflows lists the live and archived workflows matching query
+// (see ParseWorkflowFilter). If only the archive fails, it returns the live
+// workflows with an *ArchiveError.
+func (c *Client) BrowseWorkflows(ctx context.Context, query string) ([]BrowsedWorkflow, error) {
+	f, err := ParseWorkflowFilter(query, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return listBrowsable(ctx, c, f)
+}
+
+// Load fetches the browsed workflow with its nodes, from the archive if it
+// is no longer live.
+func (w BrowsedWorkflow) Load(ctx context.Context, c *Client) (*Workflow, error) {
+	if w.Archived {
+		return c.GetArchivedWorkflow(ctx, w.Metadata.UID)
+	}
+	return c.GetWorkflow(ctx, w.Metadata.Name)
+}
+
+// ArchiveError reports that the archived workflows could not be listed, for
+// example because RBAC denies them, while the live ones were.
+type ArchiveError struct {
+	Err error
+}
+
+func (e *ArchiveError) Error() string { return "list archived workflows: " + e.Err.Error() }
+
+func (e *ArchiveError) Unwrap() error { return e.Err }
+
+// listBrowsable merges live and archived workflows, live first for
+// workflows that are in both, newest first.
+func listBrowsable(ctx context.Context, c *Client, f WorkflowFilter) ([]BrowsedWorkflow, error) {
+	opts := ListOptions{LabelSelector: f.LabelSelector, NamePrefix: f.NamePrefix}
+	live, err := c.ListWorkflows(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	archived, archiveErr := c.ListArchived(ctx, opts)
+	if archiveErr != nil {
+		err = &ArchiveError{Err: archiveErr}
+	}
+
+	seen := map[string]bool{}
+	var all []BrowsedWorkflow
+	for _, wf := range live {
+		seen[wf.Metadata.UID] = true
+		if f.Match(wf) {
+			all = append(all, BrowsedWorkflow{Workflow: wf})
+		}
+	}
+	for _, wf := range archived {
+		if !seen[wf.Metadata.UID] && f.Match(wf) {
+			all = append(all, BrowsedWorkflow{Workflow: wf})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Status.StartedAt.After(all[j].Status.StartedAt)
+	})
+	return all, err
+}
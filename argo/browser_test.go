@@ -0,0 +1,56 @@
+package argo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestListBrowsable(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas": respond(`{"items": [
+			{"metadata": {"name": "sync-b", "uid": "b"}, "status": {"phase": "Running", "startedAt": "2025-03-14T10:00:00Z"}},
+			{"metadata": {"name": "other-c", "uid": "c"}, "status": {"phase": "Failed", "startedAt": "2025-03-14T11:00:00Z"}}
+		]}`),
+		"/api/v1/archived-workflows": respond(`{"items": [
+			{"metadata": {"name": "sync-a", "uid": "a"}, "status": {"phase": "Failed", "startedAt": "2025-03-13T10:00:00Z"}},
+			{"metadata": {"name": "sync-b", "uid": "b"}, "status": {"phase": "Running", "startedAt": "2025-03-14T10:00:00Z"}}
+		]}`),
+	})
+
+	items, err := listBrowsable(context.Background(), c, WorkflowFilter{NamePrefix: "sync-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("got %d workflows, want 2: %+v", len(items), items)
+	}
+	if items[0].Metadata.UID != "b" || items[0].Archived {
+		t.Errorf("items[0] = %+v, want live sync-b first", items[0])
+	}
+	if items[1].Metadata.UID != "a" || !items[1].Archived {
+		t.Errorf("items[1] = %+v, want archived sync-a", items[1])
+	}
+}
+
+func TestListBrowsableWithoutArchive(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas": respond(`{"items": [
+			{"metadata": {"name": "sync-b", "uid": "b"}, "status": {"phase": "Running", "startedAt": "2025-03-14T10:00:00Z"}}
+		]}`),
+		"/api/v1/archived-workflows": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "archived workflows forbidden", http.StatusForbidden)
+		},
+	})
+
+	items, err := listBrowsable(context.Background(), c, WorkflowFilter{})
+	var archiveErr *ArchiveError
+	var apiErr *APIError
+	if !errors.As(err, &archiveErr) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("err = %v, want an ArchiveError wrapping the 403", err)
+	}
+	if len(items) != 1 || items[0].Metadata.UID != "b" {
+		t.Errorf("items = %+v, want the live sync-b", items)
+	}
+}
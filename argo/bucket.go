@@ -0,0 +1,270 @@
+package argo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultKeyFormat   = "{{workflow.name}}/{{pod.name}}"
+	defaultS3Region    = "us-east-1"
+	defaultS3Endpoint  = "s3.amazonaws.com"
+	defaultGCSEndpoint = "storage.googleapis.com"
+
+	// emptySHA256 is the payload hash of a request without a body.
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// BucketConfig is the workflow controller's artifact repository, read
+// directly instead of through the argo-server's artifact-files proxy.
+// Credentials come from the environment: AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN for S3, and
+// GOOGLE_OAUTH_ACCESS_TOKEN or `gcloud auth print-access-token` for GCS.
+type BucketConfig struct {
+	Type      string `json:"type"` // "s3" (also MinIO) or "gcs"
+	Bucket    string `json:"bucket"`
+	Endpoint  string `json:"endpoint,omitempty"`  // host:port or URL, default AWS or Google
+	Region    string `json:"region,omitempty"`    // S3 signing region, default us-east-1
+	Insecure  bool   `json:"insecure,omitempty"`  // http for a host:port endpoint
+	KeyFormat string `json:"keyFormat,omitempty"` // the repository's keyFormat, default {{workflow.name}}/{{pod.name}}
+}
+
+// Validate reports an unknown type, a missing bucket or an unusable
+// endpoint.
+func (b BucketConfig) Validate() error {
+	if b.Type != "s3" && b.Type != "gcs" {
+		return fmt.Errorf("type: %q is neither s3 nor gcs", b.Type)
+	}
+	if b.Bucket == "" {
+		return fmt.Errorf("bucket: missing")
+	}
+	u, err := url.Parse(b.endpoint())
+	if err != nil {
+		return fmt.Errorf("endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("endpoint: %q is not an http(s) URL or host:port", b.Endpoint)
+	}
+	return nil
+}
+
+// endpoint is the endpoint URL without a trailing slash.
+func (b BucketConfig) endpoint() string {
+	endpoint := b.Endpoint
+	if endpoint == "" {
+		endpoint = defaultS3Endpoint
+		if b.Type == "gcs" {
+			endpoint = defaultGCSEndpoint
+		}
+	}
+	if !strings.Contains(endpoint, "://") {
+		scheme := "https"
+		if b.Insecure {
+			scheme = "http"
+		}
+		endpoint = scheme + "://" + endpoint
+	}
+	return strings.TrimRight(endpoint, "/")
+}
+
+// key is where the controller archived a container's log: the key recorded
+// on the node's artifact, else the key format expanded for the node's pod.
+func (b BucketConfig) key(wf *Workflow, nodeID, container string) string {
+	if outputs := wf.Status.Nodes[nodeID].Outputs; outputs != nil {
+		for _, a := range outputs.Artifacts {
+			if a.Name != container+"-logs" {
+				continue
+			}
+			if loc := a.location(b.Type); loc != nil && loc.Key != "" {
+				return loc.Key
+			}
+		}
+	}
+	format := b.KeyFormat
+	if format == "" {
+		format = defaultKeyFormat
+	}
+	created := wf.Metadata.CreationTimestamp
+	key := strings.NewReplacer(
+		"{{workflow.name}}", wf.Metadata.Name,
+		"{{workflow.namespace}}", wf.Metadata.Namespace,
+		"{{workflow.uid}}", wf.Metadata.UID,
+		"{{pod.name}}", wf.PodName(nodeID),
+		"{{workflow.creationTimestamp.Y}}", created.Format("2006"),
+		"{{workflow.creationTimestamp.m}}", created.Format("01"),
+		"{{workflow.creationTimestamp.d}}", created.Format("02"),
+	).Replace(format)
+	return strings.Trim(key, "/") + "/" + container + ".log"
+}
+
+// bucket signs requests for objects of an artifact repository.
+type bucket struct {
+	cfg BucketConfig
+
+	mu       sync.Mutex
+	gcsToken string // cached for the session, "" when none is available
+	gcsOnce  bool
+}
+
+func newBucket(cfg BucketConfig) *bucket {
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+	return &bucket{cfg: cfg}
+}
+
+// request builds the GET of an object: path-style for S3, which MinIO
+// requires, and the JSON API's media download for GCS.
+func (b *bucket) request(ctx context.Context, key string) (*http.Request, error) {
+	var u string
+	if b.cfg.Type == "gcs" {
+		u = b.cfg.endpoint() + "/storage/v1/b/" + url.PathEscape(b.cfg.Bucket) + "/o/" + url.PathEscape(key) + "?alt=media"
+	} else {
+		u = b.cfg.endpoint() + "/" + awsEscape(b.cfg.Bucket, false) + "/" + awsEscape(key, false)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if b.cfg.Type == "gcs" {
+		if token := b.googleToken(); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req, nil
+	}
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+			req.Header.Set("X-Amz-Security-Token", token)
+		}
+		signV4(req, id, secret, b.cfg.Region, time.Now())
+	}
+	return req, nil
+}
+
+// googleToken is GOOGLE_OAUTH_ACCESS_TOKEN or gcloud's access token. Without
+// either, requests go unauthenticated, as public buckets and emulators
+// such as fake-gcs-server accept.
+func (b *bucket) googleToken() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.gcsOnce {
+		b.gcsOnce = true
+		b.gcsToken = os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+		if _, err := exec.LookPath("gcloud"); b.gcsToken == "" && err == nil {
+			if out, err := runCommand(exec.Command("gcloud", "auth", "print-access-token")); err == nil {
+				b.gcsToken = strings.TrimSpace(out)
+			}
+		}
+	}
+	return b.gcsToken
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to a GET
+// without body, signing the host, Range and every X-Amz-* header.
+func signV4(req *http.Request, accessKey, secretKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptySHA256)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			params = append(params, awsEscape(k, true)+"="+awsEscape(v, true))
+		}
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		req.Method, path, strings.Join(params, "&"),
+		canonicalHeaders.String(), signedHeaders, emptySHA256,
+	}, "\n")
+	scope := day + "/" + region + "/s3/aws4_request"
+	digest := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsEscape percent-encodes everything but unreserved characters, as
+// SigV4 requires; slashes are kept in paths.
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// bucketLogs downloads a container's log from the artifact repository.
+func (c *Client) bucketLogs(ctx context.Context, wf *Workflow, nodeID, container string, progress Progress) (string, error) {
+	key := c.bucket.cfg.key(wf, nodeID, container)
+	body, err := c.retry(ctx, func(ctx context.Context, alive func()) ([]byte, error) {
+		req, err := c.bucket.request(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return c.fetch(req, progress, alive)
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s://%s/%s: %w", c.bucket.cfg.Type, c.bucket.cfg.Bucket, key, err)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,293 @@
+package argo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotCached is returned offline for anything the cache does not hold.
+var ErrNotCached = errors.New("not in the offline cache")
+
+// Cache keeps finished workflows and the logs of their completed nodes on
+// disk, so they are not downloaded again. Entries live under
+// <dir>/<workflow UID>/ (workflow.json, <node ID>/<container>.log); once
+// they outgrow maxSize, the least recently used workflows are evicted.
+type Cache struct {
+	dir     string
+	maxSize int64
+	mu      sync.Mutex
+}
+
+// CachedWorkflow describes one workflow held by the cache.
+type CachedWorkflow struct {
+	Workflow
+	Logs     int       // cached container logs
+	Size     int64     // bytes on disk
+	LastUsed time.Time // when it was last written or read
+}
+
+// DefaultCacheDir returns $XDG_CACHE_HOME/logviewer-tui (or the platform
+// equivalent).
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logviewer-tui"), nil
+}
+
+// NewCache returns a cache in dir holding at most maxSize bytes; the
+// directory is created on the first write.
+func NewCache(dir string, maxSize int64) *Cache {
+	return &Cache{dir: dir, maxSize: maxSize}
+}
+
+// Dir is the directory the cache lives in.
+func (c *Cache) Dir() string { return c.dir }
+
+// finished reports a workflow or node phase that will not change anymore.
+func finished(phase string) bool {
+	return phase == "Succeeded" || phase == "Failed" || phase == "Error"
+}
+
+// Workflow returns the most recent cached workflow with the given name or
+// UID.
+func (c *Cache) Workflow(nameOrUID string) (*Workflow, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var found *Workflow
+	for _, wf := range c.workflows() {
+		if wf.Metadata.UID != nameOrUID && wf.Metadata.Name != nameOrUID {
+			continue
+		}
+		if found == nil || wf.Metadata.CreationTimestamp.After(found.Metadata.CreationTimestamp) {
+			found = &wf
+		}
+	}
+	if found != nil {
+		c.touch(found.Metadata.UID)
+	}
+	return found, found != nil
+}
+
+// PutWorkflow stores a finished workflow; running ones are skipped.
+func (c *Cache) PutWorkflow(wf *Workflow) error {
+	if wf.Metadata.UID == "" || !finished(wf.Status.Phase) {
+		return nil
+	}
+	data, err := json.Marshal(wf)
+	if err != nil {
+		return err
+	}
+	return c.write(wf.Metadata.UID, filepath.Join(wf.Metadata.UID, "workflow.json"), data)
+}
+
+// Logs returns a node container's cached logs.
+func (c *Cache) Logs(wf *Workflow, nodeID, container string) (string, bool) {
+	if wf.Metadata.UID == "" {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := os.ReadFile(filepath.Join(c.dir, logsPath(wf, nodeID, container)))
+	if err != nil {
+		return "", false
+	}
+	c.touch(wf.Metadata.UID)
+	return string(data), true
+}
+
+// PutLogs stores a node container's logs, with the workflow they belong to,
+// once the node has completed.
+func (c *Cache) PutLogs(wf *Workflow, nodeID, container, logs string) error {
+	if wf.Metadata.UID == "" || !finished(wf.Status.Nodes[nodeID].Phase) {
+		return nil
+	}
+	if err := c.write(wf.Metadata.UID, logsPath(wf, nodeID, container), []byte(logs)); err != nil {
+		return err
+	}
+	if finished(wf.Status.Phase) {
+		return c.PutWorkflow(wf)
+	}
+	return nil
+}
+
+func logsPath(wf *Workflow, nodeID, container string) string {
+	return filepath.Join(wf.Metadata.UID, nodeID, container+".log")
+}
+
+// List returns the cached workflows, most recently used first.
+func (c *Cache) List() ([]CachedWorkflow, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []CachedWorkflow
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		cw := CachedWorkflow{Workflow: Workflow{Metadata: ObjectMeta{UID: e.Name()}}}
+		if info, err := e.Info(); err == nil {
+			cw.LastUsed = info.ModTime()
+		}
+		if data, err := os.ReadFile(filepath.Join(c.dir, e.Name(), "workflow.json")); err == nil {
+			json.Unmarshal(data, &cw.Workflow)
+		}
+		filepath.WalkDir(filepath.Join(c.dir, e.Name()), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				cw.Size += info.Size()
+			}
+			if strings.HasSuffix(path, ".log") {
+				cw.Logs++
+			}
+			return nil
+		})
+		list = append(list, cw)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].LastUsed.After(list[j].LastUsed) })
+	return list, nil
+}
+
+// Clear removes the cached workflows with the given name or UID, or every
+// entry when nameOrUID is empty, and reports how many were removed.
+func (c *Cache) Clear(nameOrUID string) (int, error) {
+	list, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for _, cw := range list {
+		if nameOrUID != "" && cw.Metadata.UID != nameOrUID && cw.Metadata.Name != nameOrUID {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, cw.Metadata.UID)); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// workflows reads every cached workflow.json; c.mu must be held.
+func (c *Cache) workflows() []Workflow {
+	paths, _ := filepath.Glob(filepath.Join(c.dir, "*", "workflow.json"))
+	var wfs []Workflow
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var wf Workflow
+		if json.Unmarshal(data, &wf) == nil {
+			wfs = append(wfs, wf)
+		}
+	}
+	return wfs
+}
+
+// touch marks a workflow as used for eviction; c.mu must be held.
+func (c *Cache) touch(uid string) {
+	now := time.Now()
+	os.Chtimes(filepath.Join(c.dir, uid), now, now)
+}
+
+// write stores data at the relative path, then evicts other workflows
+// until the cache fits maxSize again.
+func (c *Cache) write(uid, rel string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if int64(len(data)) > c.maxSize {
+		return nil
+	}
+	path := filepath.Join(c.dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	// Write to a temporary file first so readers never see half an entry.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	c.touch(uid)
+	return c.evict(uid)
+}
+
+// evict removes the least recently used workflows other than keep while the
+// cache is larger than maxSize; c.mu must be held.
+func (c *Cache) evict(keep string) error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	type usage struct {
+		uid  string
+		size int64
+		used time.Time
+	}
+	var all []usage
+	var total int64
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		u := usage{uid: e.Name()}
+		if info, err := e.Info(); err == nil {
+			u.used = info.ModTime()
+		}
+		filepath.WalkDir(filepath.Join(c.dir, e.Name()), func(_ string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				if info, err := d.Info(); err == nil {
+					u.size += info.Size()
+				}
+			}
+			return nil
+		})
+		total += u.size
+		all = append(all, u)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].used.Before(all[j].used) })
+	for _, u := range all {
+		if total <= c.maxSize {
+			break
+		}
+		if u.uid == keep {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, u.uid)); err != nil {
+			return fmt.Errorf("cache: %w", err)
+		}
+		total -= u.size
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package argo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheRoundTrip(t *testing.T) {
+	var hits atomic.Int32
+	logs := func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte("done\n"))
+	}
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas/sync-gj97n":                                        respond(testWorkflow),
+		"/artifact-files/cas/workflows/sync-gj97n/sync-gj97n-1/outputs/main-logs": logs,
+	})
+	cache := NewCache(t.TempDir(), 1<<20)
+	WithCache(cache)(c)
+
+	wf, err := c.GetWorkflow(context.Background(), "sync-gj97n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if out, err := c.GetNodeLogs(context.Background(), wf, "sync-gj97n-1"); err != nil || out != "done\n" {
+			t.Fatalf("GetNodeLogs() = %q, %v", out, err)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("artifact downloaded %d times, want once", hits.Load())
+	}
+
+	offline := NewClient(Config{Namespace: "cas"}, WithCache(cache), WithOffline(),
+		WithTokenProvider(StaticToken{Label: "none"}))
+	for _, name := range []string{"sync-gj97n", "uid-1"} {
+		wf, err := offline.GetWorkflow(context.Background(), name)
+		if err != nil || len(wf.Status.Nodes) != 3 {
+			t.Fatalf("offline GetWorkflow(%q) = %v, %v", name, wf, err)
+		}
+	}
+	if out, err := offline.GetNodeLogs(context.Background(), wf, "sync-gj97n-1"); err != nil || out != "done\n" {
+		t.Errorf("offline GetNodeLogs() = %q, %v", out, err)
+	}
+	if _, err := offline.GetNodeLogs(context.Background(), wf, "sync-gj97n-2"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("uncached logs: err = %v, want ErrNotCached", err)
+	}
+	if list, err := offline.ListWorkflows(context.Background(), ListOptions{NamePrefix: "sync-"}); err != nil || len(list) != 1 {
+		t.Errorf("offline ListWorkflows() = %d workflows, %v", len(list), err)
+	}
+}
+
+func TestCacheSkipsRunning(t *testing.T) {
+	cache := NewCache(t.TempDir(), 1<<20)
+	wf := &Workflow{
+		Metadata: ObjectMeta{Name: "wf", UID: "uid-2"},
+		Status: WorkflowStatus{Phase: "Running", Nodes: map[string]Node{
+			"n1": {Phase: "Running"}, "n2": {Phase: "Succeeded"},
+		}},
+	}
+	cache.PutWorkflow(wf)
+	cache.PutLogs(wf, "n1", "main", "partial")
+	cache.PutLogs(wf, "n2", "main", "complete")
+
+	if _, ok := cache.Workflow("wf"); ok {
+		t.Error("running workflow was cached")
+	}
+	if _, ok := cache.Logs(wf, "n1", "main"); ok {
+		t.Error("running node's logs were cached")
+	}
+	if logs, ok := cache.Logs(wf, "n2", "main"); !ok || logs != "complete" {
+		t.Errorf("Logs(n2) = %q, %v", logs, ok)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(dir, 250)
+	put := func(uid string) *Workflow {
+		wf := &Workflow{
+			Metadata: ObjectMeta{Name: uid, UID: uid},
+			Status:   WorkflowStatus{Phase: "Succeeded", Nodes: map[string]Node{"n": {Phase: "Succeeded"}}},
+		}
+		if err := cache.PutLogs(wf, "n", "main", strings.Repeat("x", 100)); err != nil {
+			t.Fatal(err)
+		}
+		return wf
+	}
+	old := put("a")
+	// Make a the least recently used one, whatever the file system's
+	// timestamp resolution.
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "a"), past, past)
+	put("b")
+	put("c")
+
+	if _, ok := cache.Logs(old, "n", "main"); ok {
+		t.Error("least recently used workflow was not evicted")
+	}
+	list, err := cache.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, cw := range list {
+		total += cw.Size
+	}
+	if total > 250 || len(list) == 0 {
+		t.Errorf("cache holds %d workflows, %d bytes; want at most 250 bytes", len(list), total)
+	}
+
+	if n, err := cache.Clear(""); err != nil || n != len(list) {
+		t.Errorf("Clear() = %d, %v; want %d", n, err, len(list))
+	}
+	if list, _ := cache.List(); len(list) != 0 {
+		t.Errorf("%d workflows left after Clear", len(list))
+	}
+}
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"team": "cas", "env": "prod"}
+	for selector, want := range map[string]bool{
+		"":                  true,
+		"team=cas":          true,
+		"team==cas,env":     true,
+		"team=cas,env!=dev": true,
+		"team=ops":          false,
+		"env!=prod":         false,
+		"owner":             false,
+	} {
+		if got := matchLabels(selector, labels); got != want {
+			t.Errorf("matchLabels(%q) = %v, want %v", selector, got, want)
+		}
+	}
+}
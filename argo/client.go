@@ -1,102 +1,329 @@
 package argo
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/charmbracelet/bubbles/list"
-	tea "github.com/charmbracelet/bubbletea"
+	"logviewer-tui/kube"
 )
 
-type item string
+// Client talks to one Argo server. It is safe for concurrent use.
+type Client struct {
+	cfg     Config
+	baseURL string
+	http    *http.Client
+	tokens  TokenProvider
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	cache   *Cache
+	offline bool
+	bucket  *bucket // nil unless Config.Artifacts is set
 
-func (i item) Title() string       { return string(i) }
-func (i item) Description() string { return "" }
-func (i item) FilterValue() string { return string(i) }
+	kubeOnce sync.Once
+	kube     *kube.Client
+	kubeErr  error
 
-type listModel struct {
-	list     list.Model
-	selected string
+	mu    sync.Mutex
+	token string
 }
 
-func (m listModel) Init() tea.Cmd {
-	return nil
+// Option customizes a Client.
+type Option func(*Client)
+
+// WithHTTPClient replaces the HTTP client, e.g. with one from httptest.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) { c.http = h }
 }
 
-func (m listModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			selected := m.list.SelectedItem()
-			if selected != nil {
-				m.selected = selected.FilterValue()
-				return m, tea.Quit
-			}
-		case "q", "esc":
-			return m, tea.Quit
+// WithKubeClient sets the client used for pod logs when a node's artifacts
+// are missing, instead of one built from the current kubeconfig.
+func WithKubeClient(k *kube.Client) Option {
+	return func(c *Client) { c.kubeOnce.Do(func() { c.kube = k }) }
+}
+
+// WithBackoff sets the delay before the first retry; it doubles after each.
+func WithBackoff(d time.Duration) Option {
+	return func(c *Client) { c.backoff = d }
+}
+
+// WithCache keeps finished workflows and their logs in cache, and serves
+// them from it instead of the server.
+func WithCache(cache *Cache) Option {
+	return func(c *Client) { c.cache = cache }
+}
+
+// WithOffline answers every request from the cache, failing with
+// ErrNotCached for anything it does not hold.
+func WithOffline() Option {
+	return func(c *Client) { c.offline = true }
+}
+
+// WithTokenProvider replaces DefaultTokenChain.
+func WithTokenProvider(p TokenProvider) Option {
+	return func(c *Client) { c.tokens = p }
+}
+
+// NewClient returns a client for cfg. The token is fetched on first use.
+func NewClient(cfg Config, opts ...Option) *Client {
+	cfg = cfg.withDefaults()
+	c := &Client{
+		cfg:     cfg,
+		baseURL: cfg.BaseURL(),
+		timeout: cfg.timeout(),
+		retries: *cfg.Retries,
+		backoff: 500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.http == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.InsecureSkipVerify {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 		}
+		c.http = &http.Client{Transport: transport}
+	}
+	if c.tokens == nil {
+		c.tokens = DefaultTokenChain(cfg)
 	}
-	var cmd tea.Cmd
-	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	if cfg.Artifacts != nil {
+		c.bucket = newBucket(*cfg.Artifacts)
+	}
+	return c
+}
+
+// kubeClient returns the pod-log client, loading the kubeconfig on first use.
+func (c *Client) kubeClient() (*kube.Client, error) {
+	c.kubeOnce.Do(func() {
+		kc, err := kube.LoadConfig()
+		if err != nil {
+			c.kubeErr = err
+			return
+		}
+		c.kube, c.kubeErr = kube.NewClient(kc)
+	})
+	return c.kube, c.kubeErr
 }
 
-func (m listModel) View() string {
-	return m.list.View()
+// Config returns the effective configuration, defaults included.
+func (c *Client) Config() Config { return c.cfg }
+
+// ErrNotFound and ErrUnauthorized match APIErrors with those statuses via
+// errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// APIError is a non-2xx response from the Argo server or, for artifacts, the
+// artifact bucket. Pod logs fail with a kube.APIError instead.
+type APIError struct {
+	StatusCode int
+	URL        string
+	Body       string
 }
 
-func promptStepSelection(steps []string) (string, error) {
-	items := make([]list.Item, len(steps))
-	for i, step := range steps {
-		items[i] = item(step)
-	}
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (%d): %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
 
-	l := list.New(items, list.NewDefaultDelegate(), 50, 20)
-	l.Title = "Select a step to view logs"
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
 
-	m := listModel{list: l}
-	program := tea.NewProgram(m)
-	ListModel, err := program.Run()
+func (c *Client) authHeader() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" {
+		return c.token, nil
+	}
+	token, err := c.tokens.Token()
 	if err != nil {
 		return "", err
 	}
-	// Type assert the final model back to listModel
-	if lm, ok := ListModel.(listModel); ok {
-		fmt.Println("✅ Step selected:", lm.selected)
-		return lm.selected, nil
+	c.token = authHeader(token)
+	return c.token, nil
+}
+
+// url joins the escaped path segments onto the base URL.
+func (c *Client) url(query url.Values, segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = url.PathEscape(s)
 	}
+	u := c.baseURL + "/" + strings.Join(escaped, "/")
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
 
-	return "", fmt.Errorf("failed to cast final model")
+// do performs an authenticated GET and returns a 200 response, whose body
+// the caller must close.
+func (c *Client) do(ctx context.Context, url string) (*http.Response, error) {
+	token, err := c.authHeader()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Authorization", token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, URL: url, Body: string(body)}
+	}
+	return resp, nil
+}
+
+// Progress receives a download's body chunk by chunk, with the bytes read so
+// far and the total size, or -1 when the server does not send one. Every
+// attempt and source starts over, so read == len(chunk) marks a new body.
+type Progress func(chunk []byte, read, total int64)
+
+// get performs an authenticated GET and returns the body of a 200 response.
+// Each attempt fails once the server sends nothing for the configured
+// timeout; 5xx responses, connection errors and such stalls are retried with
+// exponential backoff until ctx ends.
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	return c.download(ctx, url, nil)
+}
+
+// download is get reporting the body to progress as it arrives.
+func (c *Client) download(ctx context.Context, url string, progress Progress) ([]byte, error) {
+	return c.retry(ctx, func(ctx context.Context, alive func()) ([]byte, error) {
+		return c.getOnce(ctx, url, progress, alive)
+	})
 }
 
-func RunWorkflowMode(workflow string) (string, error) {
-	token, err := getArgoToken()
+// retry runs attempt until it succeeds, fails for good or ctx ends; 5xx
+// responses, connection errors and stalls are retried with exponential
+// backoff. An attempt stalls when the configured timeout passes without a
+// call to alive, which the attempt makes for every chunk of body it reads,
+// so a long download is only cut off once the server stops sending.
+func (c *Client) retry(ctx context.Context, attempt func(ctx context.Context, alive func()) ([]byte, error)) ([]byte, error) {
+	delay := c.backoff
+	for n := 0; ; n++ {
+		attemptCtx, cancel := context.WithCancelCause(ctx)
+		idle := time.AfterFunc(c.timeout, func() { cancel(context.DeadlineExceeded) })
+		body, err := attempt(attemptCtx, func() { idle.Reset(c.timeout) })
+		stalled := !idle.Stop()
+		cancel(nil)
+		if err != nil && stalled && ctx.Err() == nil {
+			err = fmt.Errorf("no response for %s: %w", c.timeout, context.DeadlineExceeded)
+		}
+		if err == nil || n >= c.retries || !retryable(err) || ctx.Err() != nil {
+			return body, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+func (c *Client) getOnce(ctx context.Context, url string, progress Progress, alive func()) ([]byte, error) {
+	resp, err := c.do(ctx, url)
 	if err != nil {
-		fmt.Println("❌ Failed to get token:", err)
-		return "", err
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	workflowUid, steps, idMap, err := fetchWorkflowSteps(workflow, token)
+	body, err := readBody(resp, progress, alive)
 	if err != nil {
-		fmt.Println("❌ Failed to fetch workflow:", err)
-		return "", err
+		return nil, fmt.Errorf("read %s: %w", url, err)
 	}
+	return body, nil
+}
 
-	step, err := promptStepSelection(steps)
+// fetch sends a request built elsewhere, such as a signed bucket GET, and
+// returns the body of a 200 response.
+func (c *Client) fetch(req *http.Request, progress Progress, alive func()) ([]byte, error) {
+	resp, err := c.http.Do(req)
 	if err != nil {
-		fmt.Println("❌ Failed to select step:", err)
-		return "", err
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, URL: req.URL.String(), Body: string(body)}
 	}
+	body, err := readBody(resp, progress, alive)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", req.URL, err)
+	}
+	return body, nil
+}
 
-	if step == "" {
-		fmt.Println("⚠️ Step selection failed — no name returned.")
+// readBody reads a response body, calling alive for each chunk and handing
+// it to progress if set.
+func readBody(resp *http.Response, progress Progress, alive func()) ([]byte, error) {
+	var body []byte
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			alive()
+			body = append(body, buf[:n]...)
+			if progress != nil {
+				progress(buf[:n], int64(len(body)), resp.ContentLength)
+			}
+		}
+		if err == io.EOF {
+			return body, nil
+		}
+		if err != nil {
+			return body, err
+		}
 	}
+}
+
+// retryable reports server-side failures, stalled attempts and transport
+// errors, which include connection resets.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
 
-	nodeID := idMap[step]
-	logs, err := fetchLogs(workflow, workflowUid, nodeID, token)
+func (c *Client) getJSON(ctx context.Context, url string, v interface{}) error {
+	body, err := c.get(ctx, url)
 	if err != nil {
-		fmt.Println("❌ Failed to fetch logs:", err)
-		return "", err
+		return err
 	}
-	return logs, nil
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+	return nil
 }
@@ -0,0 +1,431 @@
+package argo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testWorkflow = `{
+  "metadata": {"name": "sync-gj97n", "namespace": "cas", "uid": "uid-1"},
+  "status": {
+    "phase": "Failed",
+    "nodes": {
+      "sync-gj97n":   {"id": "sync-gj97n", "displayName": "sync-gj97n", "type": "Steps", "phase": "Failed"},
+      "sync-gj97n-2": {"id": "sync-gj97n-2", "displayName": "upload", "type": "Pod", "phase": "Failed"},
+      "sync-gj97n-1": {"id": "sync-gj97n-1", "displayName": "download", "type": "Pod", "phase": "Succeeded"}
+    }
+  }
+}`
+
+// newTestClient starts a stand-in Argo server serving routes (keyed by
+// escaped path) and returns a client pointed at it.
+func newTestClient(t *testing.T, routes map[string]http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			http.Error(w, "bad token "+got, http.StatusUnauthorized)
+			return
+		}
+		h, ok := routes[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(Config{Server: srv.URL, Namespace: "cas"},
+		WithHTTPClient(srv.Client()),
+		WithTokenProvider(StaticToken{Label: "test", Value: "test-token"}),
+		WithBackoff(time.Millisecond))
+}
+
+func respond(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(body)) }
+}
+
+func TestGetWorkflow(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas/sync-gj97n": respond(testWorkflow),
+	})
+
+	wf, err := c.GetWorkflow(context.Background(), "sync-gj97n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wf.Metadata.UID != "uid-1" || wf.Status.Phase != "Failed" {
+		t.Errorf("unexpected workflow %+v", wf.Metadata)
+	}
+
+	pods := wf.PodNodes()
+	if len(pods) != 2 || pods[0].DisplayName != "download" || pods[1].ID != "sync-gj97n-2" {
+		t.Errorf("PodNodes() = %+v", pods)
+	}
+}
+
+func TestGetWorkflowNotFound(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	_, err := c.GetWorkflow(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("err = %#v, want *APIError with 404", err)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	c := newTestClient(t, nil)
+	c.tokens = StaticToken{Label: "test", Value: "wrong"}
+
+	_, err := c.GetWorkflow(context.Background(), "sync-gj97n")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestGetNodeLogs(t *testing.T) {
+	wf := &Workflow{Metadata: ObjectMeta{Name: "sync-gj97n", UID: "uid-1"}}
+
+	t.Run("primary", func(t *testing.T) {
+		c := newTestClient(t, map[string]http.HandlerFunc{
+			"/artifact-files/cas/workflows/sync-gj97n/sync-gj97n-1/outputs/main-logs": respond("live\n"),
+		})
+		logs, err := c.GetNodeLogs(context.Background(), wf, "sync-gj97n-1")
+		if err != nil || logs != "live\n" {
+			t.Fatalf("GetNodeLogs() = %q, %v", logs, err)
+		}
+	})
+
+	t.Run("archived fallback", func(t *testing.T) {
+		c := newTestClient(t, map[string]http.HandlerFunc{
+			"/artifact-files/cas/archived-workflows/uid-1/sync-gj97n-1/outputs/main-logs": respond("archived\n"),
+		})
+		logs, err := c.GetNodeLogs(context.Background(), wf, "sync-gj97n-1")
+		if err != nil || logs != "archived\n" {
+			t.Fatalf("GetNodeLogs() = %q, %v", logs, err)
+		}
+	})
+
+	t.Run("empty node", func(t *testing.T) {
+		c := newTestClient(t, nil)
+		if _, err := c.GetNodeLogs(context.Background(), wf, ""); err == nil {
+			t.Fatal("expected an error for an empty node ID")
+		}
+	})
+}
+
+func TestListArchived(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/archived-workflows": func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			if q.Get("namespace") != "cas" || q.Get("listOptions.labelSelector") != "team=cas" || q.Get("namePrefix") != "sync-" {
+				http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"items": [{"metadata": {"name": "sync-abc", "uid": "uid-2"}}]}`))
+		},
+	})
+
+	items, err := c.ListArchived(context.Background(), ListOptions{LabelSelector: "team=cas", NamePrefix: "sync-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Metadata.UID != "uid-2" {
+		t.Errorf("ListArchived() = %+v", items)
+	}
+}
+
+func TestFindWorkflow(t *testing.T) {
+	const uid = "9f9aab90-319b-4655-905c-7ea2db0ef550"
+	archivedWorkflow := strings.Replace(testWorkflow, `"uid": "uid-1"`, `"uid": "`+uid+`"`, 1)
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/archived-workflows": respond(`{"items": [
+			{"metadata": {"name": "sync-gj97n", "uid": "older", "creationTimestamp": "2025-03-01T00:00:00Z"}},
+			{"metadata": {"name": "sync-gj97n", "uid": "` + uid + `", "creationTimestamp": "2025-03-02T00:00:00Z"}},
+			{"metadata": {"name": "sync-gj97n-retry", "uid": "other", "creationTimestamp": "2025-03-03T00:00:00Z"}}
+		]}`),
+		"/api/v1/archived-workflows/" + uid:                                                 respond(archivedWorkflow),
+		"/artifact-files/cas/archived-workflows/" + uid + "/sync-gj97n-1/outputs/main-logs": respond("archived\n"),
+		// A newer run of the same name, whose node IDs are the same.
+		"/artifact-files/cas/workflows/sync-gj97n/sync-gj97n-1/outputs/main-logs": respond("newer run\n"),
+	})
+
+	for _, name := range []string{"sync-gj97n", uid} {
+		wf, err := c.FindWorkflow(context.Background(), name)
+		if err != nil {
+			t.Fatalf("FindWorkflow(%q): %v", name, err)
+		}
+		if wf.Metadata.UID != uid || !wf.Archived || len(wf.PodNodes()) != 2 {
+			t.Errorf("FindWorkflow(%q) = %+v, archived %v", name, wf.Metadata, wf.Archived)
+		}
+	}
+
+	wf, _ := c.FindWorkflow(context.Background(), "sync-gj97n")
+	if logs, err := c.GetNodeLogs(context.Background(), wf, "sync-gj97n-1"); err != nil || logs != "archived\n" {
+		t.Errorf("GetNodeLogs() = %q, %v", logs, err)
+	}
+
+	if _, err := c.FindWorkflow(context.Background(), "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindWorkflow(gone) err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestContextCancelled(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas/sync-gj97n": respond(testWorkflow),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.GetWorkflow(ctx, "sync-gj97n"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas/sync-gj97n": func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				http.Error(w, "try again", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(testWorkflow))
+		},
+	})
+
+	if _, err := c.GetWorkflow(context.Background(), "sync-gj97n"); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("server called %d times, want 3", calls.Load())
+	}
+
+	c.retries = 1
+	calls.Store(0)
+	var apiErr *APIError
+	if _, err := c.GetWorkflow(context.Background(), "sync-gj97n"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want the last 503", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("server called %d times, want 2", calls.Load())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas/sync-gj97n": func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		},
+	})
+	c.timeout = 20 * time.Millisecond
+	c.retries = 0
+
+	if _, err := c.GetWorkflow(context.Background(), "sync-gj97n"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestSlowBody(t *testing.T) {
+	var calls atomic.Int32
+	var stall atomic.Bool
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas/sync-gj97n/log": func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			for i := 0; i < 6; i++ {
+				fmt.Fprintf(w, "line %d\n", i)
+				w.(http.Flusher).Flush()
+				if stall.Load() && i == 2 {
+					<-r.Context().Done()
+					return
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+		},
+	})
+	c.timeout = 150 * time.Millisecond
+	c.retries = 1
+
+	// The body takes twice the timeout, but a chunk arrives every 50ms.
+	body, err := c.get(context.Background(), c.baseURL+"/api/v1/workflows/cas/sync-gj97n/log")
+	if err != nil || strings.Count(string(body), "\n") != 6 || calls.Load() != 1 {
+		t.Fatalf("get() = %q, %v after %d calls, want 6 lines in one call", body, err, calls.Load())
+	}
+
+	stall.Store(true)
+	calls.Store(0)
+	if _, err := c.get(context.Background(), c.baseURL+"/api/v1/workflows/cas/sync-gj97n/log"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("server called %d times, want a stalled body retried once", calls.Load())
+	}
+}
+
+func TestLogFetchError(t *testing.T) {
+	wf := &Workflow{Metadata: ObjectMeta{Name: "sync-gj97n", UID: "uid-1"}}
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/artifact-files/cas/archived-workflows/uid-1/sync-gj97n-1/outputs/main-logs": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "archive down", http.StatusBadGateway)
+		},
+	})
+	WithKubeClient(newFakeKube(t, nil))(c)
+
+	_, err := c.GetNodeLogs(context.Background(), wf, "sync-gj97n-1")
+	var fetchErr *LogFetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("err = %v, want *LogFetchError", err)
+	}
+	var primary, fallback *APIError
+	if !errors.As(fetchErr.Primary, &primary) || primary.StatusCode != http.StatusNotFound {
+		t.Errorf("Primary = %v, want 404", fetchErr.Primary)
+	}
+	if !errors.As(fetchErr.Fallback, &fallback) || fallback.StatusCode != http.StatusBadGateway {
+		t.Errorf("Fallback = %v, want 502", fetchErr.Fallback)
+	}
+	if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "archive down") {
+		t.Errorf("error hides a source: %v", err)
+	}
+}
+
+func TestArchivedLogsStayInArchive(t *testing.T) {
+	wf := &Workflow{Metadata: ObjectMeta{Name: "sync-gj97n", UID: "uid-1"}, Archived: true}
+	var kube atomic.Bool
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		// A newer run of the same name, whose node IDs are the same.
+		"/artifact-files/cas/workflows/sync-gj97n/sync-gj97n-1/outputs/main-logs": respond("newer run\n"),
+	})
+	WithKubeClient(newKubeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		kube.Store(true)
+		w.Write([]byte("newer pod\n"))
+	}))(c)
+
+	logs, err := c.GetNodeLogs(context.Background(), wf, "sync-gj97n-1")
+	var fetchErr *LogFetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("GetNodeLogs() = %q, %v, want *LogFetchError", logs, err)
+	}
+	if fetchErr.Fallback != nil || fetchErr.Pod != nil || kube.Load() {
+		t.Errorf("archived run fell back to the live run: %v", err)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDownloadProgress(t *testing.T) {
+	wf := &Workflow{Metadata: ObjectMeta{Name: "sync-gj97n"}}
+	logs := strings.Repeat(`{"level":"INFO","message":"hello"}`+"\n", 5000)
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/artifact-files/cas/workflows/sync-gj97n/sync-gj97n-1/outputs/main-logs": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", strconv.Itoa(len(logs)))
+			w.Write([]byte(logs))
+		},
+	})
+
+	var got strings.Builder
+	var calls int
+	var lastRead, lastTotal int64
+	out, err := c.DownloadContainerLogs(context.Background(), wf, "sync-gj97n-1", "main", func(chunk []byte, read, total int64) {
+		calls++
+		got.Write(chunk)
+		lastRead, lastTotal = read, total
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != logs || got.String() != logs {
+		t.Errorf("chunks do not add up to the body: %d of %d bytes", got.Len(), len(logs))
+	}
+	if calls < 2 || lastRead != int64(len(logs)) || lastTotal != int64(len(logs)) {
+		t.Errorf("calls = %d, read = %d, total = %d; want several calls ending at %d", calls, lastRead, lastTotal, len(logs))
+	}
+}
+
+func TestTokenChain(t *testing.T) {
+	chain := TokenChain{
+		StaticToken{Label: "first"},
+		FileToken{Path: "/does/not/exist"},
+		StaticToken{Label: "last", Value: "abc"},
+	}
+	token, err := chain.Token()
+	if err != nil || token != "Bearer abc" {
+		t.Fatalf("Token() = %q, %v", token, err)
+	}
+
+	_, err = chain[:2].Token()
+	var chainErr *TokenChainError
+	if !errors.As(err, &chainErr) || len(chainErr.Attempts) != 2 {
+		t.Fatalf("err = %v, want TokenChainError with 2 attempts", err)
+	}
+	if !strings.Contains(err.Error(), "first: not configured") {
+		t.Errorf("error does not explain skipped provider: %v", err)
+	}
+}
+
+func TestConfigBaseURL(t *testing.T) {
+	insecure := false
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{}, "http://localhost:2746"},
+		{Config{Server: "argo.example.com:443"}, "https://argo.example.com:443"},
+		{Config{Server: "localhost:2746", Secure: &insecure}, "http://localhost:2746"},
+		{Config{Server: "https://argo.example.com/", BasePath: "/argo/"}, "https://argo.example.com/argo"},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.BaseURL(); got != tt.want {
+			t.Errorf("%+v.BaseURL() = %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestConfigWithEnv(t *testing.T) {
+	env := map[string]string{
+		"ARGO_SERVER":               "argo:2746",
+		"ARGO_NAMESPACE":            "jobs",
+		"ARGO_SECURE":               "false",
+		"ARGO_INSECURE_SKIP_VERIFY": "true",
+	}
+	cfg, err := Config{Namespace: "cas"}.WithEnv(func(k string) string { return env[k] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL() != "http://argo:2746" || cfg.Namespace != "jobs" || !cfg.InsecureSkipVerify {
+		t.Errorf("WithEnv() = %+v", cfg)
+	}
+
+	env["ARGO_SECURE"] = "maybe"
+	if _, err := (Config{}).WithEnv(func(k string) string { return env[k] }); err == nil {
+		t.Error("expected an error for ARGO_SECURE=maybe")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	negative := -1
+	for _, cfg := range []Config{
+		{Server: "ftp://argo"},
+		{Timeout: "soon"},
+		{Timeout: "0s"},
+		{Retries: &negative},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%+v.Validate() = nil, want an error", cfg)
+		}
+	}
+	if err := (Config{Server: "argo:2746", Timeout: "10s"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
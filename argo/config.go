@@ -0,0 +1,148 @@
+package argo
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultServer    = "http://localhost:2746"
+	defaultNamespace = "cas"
+	defaultTimeout   = 30 * time.Second
+	defaultRetries   = 3
+)
+
+// Config points the client at an Argo server. Empty fields fall back to the
+// port-forwarded defaults. Server accepts either a full URL or, like the argo
+// CLI's ARGO_SERVER, a bare host:port whose scheme is chosen by Secure.
+type Config struct {
+	Server             string `json:"server,omitempty"`
+	Namespace          string `json:"namespace,omitempty"`
+	ArtifactRepository string `json:"artifactRepository,omitempty"` // namespace segment of artifact-files URLs, defaults to Namespace
+	BasePath           string `json:"basePath,omitempty"`           // argo-server --basehref, e.g. /argo
+	Secure             *bool  `json:"secure,omitempty"`             // https for a bare host:port, default true
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	TokenFile          string `json:"tokenFile,omitempty"`       // file holding a bearer token
+	Token              string `json:"-"`                         // from --token, never saved
+	Timeout            string `json:"timeout,omitempty"`         // how long an attempt may wait for data, e.g. "30s"
+	Retries            *int   `json:"retries,omitempty"`         // extra attempts after a 5xx or connection error, default 3
+	PortForward        bool   `json:"portForward,omitempty"`     // run kubectl port-forward to the argo-server service instead of using Server
+	ServerNamespace    string `json:"serverNamespace,omitempty"` // namespace of the argo-server service, default argo
+	ServerService      string `json:"serverService,omitempty"`   // name of the argo-server service, default argo-server
+
+	// Artifacts, when set, is read directly for logs before the server.
+	Artifacts *BucketConfig `json:"artifacts,omitempty"`
+}
+
+// WithEnv overrides the config with the argo CLI's environment variables:
+// ARGO_SERVER, ARGO_NAMESPACE, ARGO_SECURE, ARGO_INSECURE_SKIP_VERIFY and
+// ARGO_BASE_HREF.
+func (c Config) WithEnv(getenv func(string) string) (Config, error) {
+	if v := getenv("ARGO_SERVER"); v != "" {
+		c.Server = v
+	}
+	if v := getenv("ARGO_NAMESPACE"); v != "" {
+		c.Namespace = v
+	}
+	if v := getenv("ARGO_BASE_HREF"); v != "" {
+		c.BasePath = v
+	}
+	if v := getenv("ARGO_SECURE"); v != "" {
+		secure, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("ARGO_SECURE: %w", err)
+		}
+		c.Secure = &secure
+	}
+	if v := getenv("ARGO_INSECURE_SKIP_VERIFY"); v != "" {
+		skip, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("ARGO_INSECURE_SKIP_VERIFY: %w", err)
+		}
+		c.InsecureSkipVerify = skip
+	}
+	return c, nil
+}
+
+func (c Config) withDefaults() Config {
+	if c.Server == "" {
+		c.Server = defaultServer
+	}
+	if c.Namespace == "" {
+		c.Namespace = defaultNamespace
+	}
+	if c.ArtifactRepository == "" {
+		c.ArtifactRepository = c.Namespace
+	}
+	if c.Retries == nil {
+		retries := defaultRetries
+		c.Retries = &retries
+	}
+	if c.ServerNamespace == "" {
+		c.ServerNamespace = defaultServerNamespace
+	}
+	if c.ServerService == "" {
+		c.ServerService = defaultServerService
+	}
+	return c
+}
+
+// timeout is how long a request attempt may wait for data; Validate has
+// checked it.
+func (c Config) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil {
+		return d
+	}
+	return defaultTimeout
+}
+
+// BaseURL is the server URL including the base path, without a trailing
+// slash.
+func (c Config) BaseURL() string {
+	c = c.withDefaults()
+	server := c.Server
+	if !strings.Contains(server, "://") {
+		scheme := "https"
+		if c.Secure != nil && !*c.Secure {
+			scheme = "http"
+		}
+		server = scheme + "://" + server
+	}
+	base := strings.Trim(c.BasePath, "/")
+	if base != "" {
+		base = "/" + base
+	}
+	return strings.TrimRight(server, "/") + base
+}
+
+// Validate reports a server that is neither an http(s) URL nor a host:port,
+// an unusable timeout or retry count, and an incomplete artifact bucket.
+func (c Config) Validate() error {
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("timeout: %q is not a positive duration such as 30s", c.Timeout)
+		}
+	}
+	if c.Retries != nil && *c.Retries < 0 {
+		return fmt.Errorf("retries: must not be negative")
+	}
+	if c.Artifacts != nil {
+		if err := c.Artifacts.Validate(); err != nil {
+			return fmt.Errorf("artifacts: %w", err)
+		}
+	}
+	if c.Server == "" {
+		return nil
+	}
+	u, err := url.Parse(c.BaseURL())
+	if err != nil {
+		return fmt.Errorf("server: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("server: %q is not an http(s) URL or host:port", c.Server)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package argo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkflowFilter narrows the workflow browser. LabelSelector is applied by
+// the server; the rest is matched locally.
+type WorkflowFilter struct {
+	NamePrefix    string
+	LabelSelector string
+	Phase         string
+	Since         time.Time
+	Until         time.Time
+}
+
+// ParseWorkflowFilter parses a query such as
+//
+//	sync- label:team=cas phase:Failed since:12h until:2025-03-14
+//
+// A bare word is a name prefix; since/until take a duration back from now,
+// a date or an RFC 3339 timestamp.
+func ParseWorkflowFilter(query string, now time.Time) (WorkflowFilter, error) {
+	var f WorkflowFilter
+	for _, field := range strings.Fields(query) {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			f.NamePrefix = field
+			continue
+		}
+		switch key {
+		case "label", "l":
+			if f.LabelSelector != "" {
+				f.LabelSelector += ","
+			}
+			f.LabelSelector += value
+		case "phase", "p":
+			f.Phase = value
+		case "since", "until":
+			t, err := parseTimeBound(value, now)
+			if err != nil {
+				return f, fmt.Errorf("%s: %w", key, err)
+			}
+			if key == "since" {
+				f.Since = t
+			} else {
+				f.Until = t
+			}
+		default:
+			return f, fmt.Errorf("unknown filter %q (use label:, phase:, since: or until:)", key)
+		}
+	}
+	return f, nil
+}
+
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a duration (12h) nor a date (2006-01-02)", value)
+}
+
+// Match reports whether wf passes the local part of the filter.
+func (f WorkflowFilter) Match(wf Workflow) bool {
+	if f.NamePrefix != "" && !strings.HasPrefix(wf.Metadata.Name, f.NamePrefix) {
+		return false
+	}
+	if f.Phase != "" && !strings.EqualFold(wf.Status.Phase, f.Phase) {
+		return false
+	}
+	started := wf.Status.StartedAt
+	if started.IsZero() {
+		started = wf.Metadata.CreationTimestamp
+	}
+	if !f.Since.IsZero() && started.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && started.After(f.Until) {
+		return false
+	}
+	return true
+}
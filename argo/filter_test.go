@@ -0,0 +1,57 @@
+package argo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWorkflowFilter(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	f, err := ParseWorkflowFilter("sync- label:team=cas l:env=prod phase:Failed since:12h until:2025-03-14", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := WorkflowFilter{
+		NamePrefix:    "sync-",
+		LabelSelector: "team=cas,env=prod",
+		Phase:         "Failed",
+		Since:         now.Add(-12 * time.Hour),
+		Until:         time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+	}
+	if f != want {
+		t.Errorf("ParseWorkflowFilter() = %+v, want %+v", f, want)
+	}
+
+	for _, bad := range []string{"since:yesterday", "owner:me"} {
+		if _, err := ParseWorkflowFilter(bad, now); err == nil {
+			t.Errorf("ParseWorkflowFilter(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestWorkflowFilterMatch(t *testing.T) {
+	started := time.Date(2025, 3, 14, 6, 0, 0, 0, time.UTC)
+	wf := Workflow{
+		Metadata: ObjectMeta{Name: "sync-abc"},
+		Status:   WorkflowStatus{Phase: "Failed", StartedAt: started},
+	}
+	tests := []struct {
+		name string
+		f    WorkflowFilter
+		want bool
+	}{
+		{"empty", WorkflowFilter{}, true},
+		{"prefix", WorkflowFilter{NamePrefix: "sync-"}, true},
+		{"other prefix", WorkflowFilter{NamePrefix: "backup-"}, false},
+		{"phase ignores case", WorkflowFilter{Phase: "failed"}, true},
+		{"other phase", WorkflowFilter{Phase: "Succeeded"}, false},
+		{"inside window", WorkflowFilter{Since: started.Add(-time.Hour), Until: started.Add(time.Hour)}, true},
+		{"before window", WorkflowFilter{Since: started.Add(time.Hour)}, false},
+		{"after window", WorkflowFilter{Until: started.Add(-time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Match(wf); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
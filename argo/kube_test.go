@@ -0,0 +1,100 @@
+package argo
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"logviewer-tui/kube"
+)
+
+// newFakeKube starts a TLS stand-in for the Kubernetes API that serves pod
+// logs (keyed by "namespace/pod/container") and pods ("namespace/pod").
+func newFakeKube(t *testing.T, logs map[string]string) *kube.Client {
+	t.Helper()
+	return newKubeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/"), "/")
+		name := parts[0] + "/" + parts[len(parts)-1]
+		switch {
+		case len(parts) == 4 && parts[1] == "pods" && parts[3] == "log":
+			name = parts[0] + "/" + parts[2] + "/" + r.URL.Query().Get("container")
+		case len(parts) != 3 || parts[1] != "pods":
+			http.NotFound(w, r)
+			return
+		}
+		body, ok := logs[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	})
+}
+
+// newKubeServer starts a TLS stand-in for the Kubernetes API that checks the
+// token and hands requests to h.
+func newKubeServer(t *testing.T, h http.HandlerFunc) *kube.Client {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer kube-token" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	k, err := kube.NewClient(kube.Config{Server: srv.URL, CAData: ca, Token: "kube-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestGetNodeLogsPodFallback(t *testing.T) {
+	wf := &Workflow{
+		Metadata: ObjectMeta{Name: "sync-gj97n", Namespace: "cas", UID: "uid-1"},
+		Status: WorkflowStatus{Nodes: map[string]Node{
+			"sync-gj97n-1234567890": {Name: "sync-gj97n[0].upload", TemplateName: "upload", Type: "Pod"},
+		}},
+	}
+	k := newFakeKube(t, map[string]string{"cas/sync-gj97n-upload-2804518603/main": "from pod\n"})
+	c := newTestClient(t, nil)
+	WithKubeClient(k)(c)
+
+	logs, err := c.GetNodeLogs(context.Background(), wf, "sync-gj97n-1234567890")
+	if err != nil || logs != "from pod\n" {
+		t.Fatalf("GetNodeLogs() = %q, %v", logs, err)
+	}
+
+	_, err = c.GetNodeLogs(context.Background(), wf, "sync-gj97n-404")
+	if err == nil || !strings.Contains(err.Error(), "pod logs") {
+		t.Errorf("err = %v, want every source listed", err)
+	}
+}
+
+func TestNodeContainers(t *testing.T) {
+	wf := &Workflow{
+		Metadata: ObjectMeta{Name: "sync-gj97n", Namespace: "cas", Annotations: map[string]string{podNameFormatAnnotation: "v1"}},
+		Status: WorkflowStatus{Nodes: map[string]Node{
+			"sync-gj97n-1": {Name: "sync-gj97n[0].upload", Type: "Pod", Outputs: &Outputs{Artifacts: []Artifact{
+				{Name: "result"}, {Name: "main-logs"}, {Name: "proxy-logs"},
+			}}},
+		}},
+	}
+	k := newFakeKube(t, map[string]string{
+		"cas/sync-gj97n-1": `{"spec": {"initContainers": [{"name": "init"}], "containers": [{"name": "wait"}, {"name": "main"}]}}`,
+	})
+	c := newTestClient(t, nil)
+	WithKubeClient(k)(c)
+
+	got := c.NodeContainers(context.Background(), wf, "sync-gj97n-1")
+	want := []string{"init", "wait", "main", "proxy"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("NodeContainers() = %q, want %q", got, want)
+	}
+}
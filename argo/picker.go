@@ -0,0 +1,155 @@
+package argo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stepItem is a row of the step tree; only Pod nodes can be opened or marked.
+type stepItem struct {
+	TreeRow
+	Marked bool
+}
+
+func (i stepItem) Title() string {
+	mark := ""
+	if i.Type == "Pod" {
+		mark = "[ ] "
+		if i.Marked {
+			mark = "[x] "
+		}
+	}
+	title := strings.Repeat("  ", i.Depth) + mark + PhaseIcon(i.Phase) + " " + i.DisplayName
+	switch {
+	case i.Attempts > 0:
+		title += fmt.Sprintf(" (%d attempts)", i.Attempts)
+	case i.Attempt > 0:
+		title += fmt.Sprintf(" (attempt %d)", i.Attempt)
+	}
+	return title
+}
+
+func (i stepItem) Description() string {
+	parts := []string{i.Type, i.Phase}
+	if d := i.Duration(); d > 0 {
+		parts = append(parts, FormatDuration(d))
+	}
+	if i.Message != "" {
+		parts = append(parts, i.Message)
+	}
+	return strings.Repeat("  ", i.Depth) + strings.Join(parts, " · ")
+}
+
+func (i stepItem) FilterValue() string { return i.DisplayName }
+
+// StepList is an embeddable list of a workflow's node tree. The owner
+// decides which keys open a step; everything else goes to Update.
+type StepList struct {
+	list list.Model
+}
+
+// NewStepList lists wf's nodes, starting on the first failed pod, which is
+// usually the one to look at.
+func NewStepList(wf *Workflow) StepList {
+	rows := wf.NodeTree()
+	items := make([]list.Item, len(rows))
+	cursor := 0
+	for i, row := range rows {
+		items[i] = stepItem{TreeRow: row}
+		if cursor == 0 && row.Type == "Pod" && (row.Phase == "Failed" || row.Phase == "Error") {
+			cursor = i
+		}
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 80, 20)
+	name := wf.Metadata.Name
+	if wf.Archived {
+		name += " (archived)"
+	}
+	l.Title = fmt.Sprintf("%s %s — select a step to view logs", PhaseIcon(wf.Status.Phase), name)
+	l.SetShowHelp(false)
+	l.DisableQuitKeybindings()
+	l.Select(cursor)
+	return StepList{list: l}
+}
+
+// SetWorkflow refreshes the rows from an updated wf, keeping marks and the
+// highlighted node.
+func (s *StepList) SetWorkflow(wf *Workflow) tea.Cmd {
+	selected, _ := s.Selected()
+	marked := map[string]bool{}
+	for _, row := range s.Marked() {
+		marked[row.ID] = true
+	}
+	rows := wf.NodeTree()
+	items := make([]list.Item, len(rows))
+	for i, row := range rows {
+		items[i] = stepItem{TreeRow: row, Marked: marked[row.ID]}
+	}
+	cmd := s.list.SetItems(items)
+	s.Select(selected.ID)
+	return cmd
+}
+
+func (s *StepList) SetSize(width, height int) { s.list.SetSize(width, height) }
+
+// Filtering reports whether the user is typing a name filter, in which case
+// all keys belong to the list.
+func (s StepList) Filtering() bool { return s.list.FilterState() == list.Filtering }
+
+// Selected returns the highlighted node.
+func (s StepList) Selected() (TreeRow, bool) {
+	item, ok := s.list.SelectedItem().(stepItem)
+	return item.TreeRow, ok
+}
+
+// Select highlights the node with the given ID.
+func (s *StepList) Select(nodeID string) {
+	for i, item := range s.list.Items() {
+		if item.(stepItem).ID == nodeID {
+			s.list.Select(i)
+			return
+		}
+	}
+}
+
+// ToggleMark marks or unmarks the highlighted pod for a merged view. The
+// returned command refreshes a filtered list.
+func (s *StepList) ToggleMark() tea.Cmd {
+	item, ok := s.list.SelectedItem().(stepItem)
+	if !ok || item.Type != "Pod" {
+		return nil
+	}
+	item.Marked = !item.Marked
+	// Index() is relative to the filtered items, SetItem to all of them.
+	for i, it := range s.list.Items() {
+		if it.(stepItem).ID == item.ID {
+			return s.list.SetItem(i, item)
+		}
+	}
+	return nil
+}
+
+// Marked returns the marked pods in tree order.
+func (s StepList) Marked() []TreeRow {
+	var rows []TreeRow
+	for _, item := range s.list.Items() {
+		if item := item.(stepItem); item.Marked {
+			rows = append(rows, item.TreeRow)
+		}
+	}
+	return rows
+}
+
+func (s StepList) NewStatusMessage(msg string) tea.Cmd { return s.list.NewStatusMessage(msg) }
+
+func (s StepList) Update(msg tea.Msg) (StepList, tea.Cmd) {
+	var cmd tea.Cmd
+	s.list, cmd = s.list.Update(msg)
+	return s, cmd
+}
+
+func (s StepList) View() string { return s.list.View() }
@@ -0,0 +1,144 @@
+package argo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultServerNamespace = "argo"
+	defaultServerService   = "argo-server"
+	argoServerPort         = 2746
+
+	// portForwardReady bounds how long kubectl may take to open the tunnel.
+	portForwardReady = 15 * time.Second
+)
+
+// PortForward is a `kubectl port-forward` to the argo-server service,
+// listening on a free local port.
+type PortForward struct {
+	Port int
+
+	cmd    *exec.Cmd
+	stderr *syncBuffer
+	exited chan struct{}
+	err    error // set before exited is closed
+}
+
+// StartPortForward runs kubectl port-forward to the argo-server service of
+// cfg and waits until its local port accepts connections. Close it to stop
+// kubectl.
+func StartPortForward(ctx context.Context, cfg Config) (*PortForward, error) {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return nil, fmt.Errorf("port-forward needs kubectl, which is not in PATH; install it or run the port-forward yourself and pass --argo-server")
+	}
+	port, err := freePort()
+	if err != nil {
+		return nil, fmt.Errorf("port-forward: no free local port: %w", err)
+	}
+	cfg = cfg.withDefaults()
+	target := "svc/" + cfg.ServerService
+	pf := &PortForward{
+		Port:   port,
+		cmd:    exec.Command("kubectl", "port-forward", "-n", cfg.ServerNamespace, "--address", "127.0.0.1", target, fmt.Sprintf("%d:%d", port, argoServerPort)),
+		stderr: &syncBuffer{},
+		exited: make(chan struct{}),
+	}
+	pf.cmd.Stderr = pf.stderr
+	if err := pf.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("port-forward: %w", err)
+	}
+	go func() {
+		pf.err = pf.cmd.Wait()
+		close(pf.exited)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, portForwardReady)
+	defer cancel()
+	if err := pf.wait(ctx); err != nil {
+		pf.Close()
+		return nil, fmt.Errorf("port-forward to %s in namespace %s: %w", target, cfg.ServerNamespace, err)
+	}
+	return pf, nil
+}
+
+// wait polls the local port until it accepts a connection, kubectl exits or
+// ctx ends.
+func (pf *PortForward) wait(ctx context.Context) error {
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(pf.Port))
+	for {
+		if conn, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case <-pf.exited:
+			if msg := strings.TrimSpace(pf.stderr.String()); msg != "" {
+				return fmt.Errorf("kubectl exited: %s", msg)
+			}
+			return fmt.Errorf("kubectl exited: %v", pf.err)
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("not ready after %s", portForwardReady)
+			}
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Server is the address to point the client at.
+func (pf *PortForward) Server() string { return "127.0.0.1:" + strconv.Itoa(pf.Port) }
+
+// Close stops kubectl and waits for it to exit. A nil PortForward is a
+// no-op, for callers that forward only on demand.
+func (pf *PortForward) Close() error {
+	if pf == nil {
+		return nil
+	}
+	select {
+	case <-pf.exited:
+		return nil
+	default:
+	}
+	if err := pf.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	<-pf.exited
+	return nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// syncBuffer collects kubectl's stderr while it runs.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,92 @@
+package argo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeKubectl puts a kubectl on PATH that re-runs this test binary as
+// TestHelperKubectl with mode in its environment.
+func fakeKubectl(t *testing.T, mode string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\nFAKE_KUBECTL=%s exec %q -test.run='^TestHelperKubectl$' -- \"$@\"\n", mode, os.Args[0])
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+// TestHelperKubectl stands in for kubectl port-forward: it listens on the
+// requested local port, or fails like kubectl does for a missing service.
+func TestHelperKubectl(t *testing.T) {
+	mode := os.Getenv("FAKE_KUBECTL")
+	if mode == "" {
+		t.Skip("helper process")
+	}
+	args := os.Args
+	for i, a := range args {
+		if a == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	if mode == "fail" {
+		fmt.Fprintln(os.Stderr, `Error from server (NotFound): services "argo-server" not found`)
+		os.Exit(1)
+	}
+	local, _, _ := strings.Cut(args[len(args)-1], ":")
+	l, err := net.Listen("tcp", "127.0.0.1:"+local)
+	if err != nil {
+		os.Exit(2)
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			os.Exit(0)
+		}
+		conn.Close()
+	}
+}
+
+func TestPortForward(t *testing.T) {
+	fakeKubectl(t, "ok")
+	pf, err := StartPortForward(context.Background(), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", pf.Server())
+	if err != nil {
+		t.Fatalf("port-forward not listening: %v", err)
+	}
+	conn.Close()
+	if err := pf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.Dial("tcp", pf.Server()); err == nil {
+		t.Error("port-forward still listening after Close")
+	}
+}
+
+func TestPortForwardErrors(t *testing.T) {
+	t.Run("kubectl fails", func(t *testing.T) {
+		fakeKubectl(t, "fail")
+		_, err := StartPortForward(context.Background(), Config{})
+		if err == nil || !strings.Contains(err.Error(), `services "argo-server" not found`) {
+			t.Errorf("err = %v, want kubectl's stderr", err)
+		}
+	})
+
+	t.Run("no kubectl", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		_, err := StartPortForward(context.Background(), Config{})
+		if err == nil || !strings.Contains(err.Error(), "kubectl") {
+			t.Errorf("err = %v, want kubectl not found", err)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package argo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// podNameFormatAnnotation records which pod naming scheme the controller
+// used for a workflow.
+const podNameFormatAnnotation = "workflows.argoproj.io/pod-name-format"
+
+// PodName returns the name of the pod that ran a node. Workflows annotated
+// with the v1 format name pods after the node ID; v2, the default since Argo
+// 3.4, uses <workflow>-<template>-<fnv32a of the node name>, where the
+// template of a templateRef step is the referenced template's name.
+func (w Workflow) PodName(nodeID string) string {
+	node := w.Status.Nodes[nodeID]
+	if w.Metadata.Annotations[podNameFormatAnnotation] == "v1" || node.Name == "" {
+		return nodeID
+	}
+	if node.Name == w.Metadata.Name {
+		return w.Metadata.Name
+	}
+	prefix := w.Metadata.Name
+	if template := node.template(); !strings.Contains(node.Name, ".inline") && template != "" {
+		prefix += "-" + template
+	}
+	// Keep room for "-" and the hash within the Kubernetes name limit.
+	if limit := 253 - 1 - 10; len(prefix) > limit {
+		prefix = prefix[:limit]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(node.Name))
+	return fmt.Sprintf("%s-%d", prefix, h.Sum32())
+}
+
+// LogStream reads the lines of a running container as the Argo server or
+// the Kubernetes API sends them.
+type LogStream struct {
+	body io.ReadCloser
+	next func() (string, error)
+}
+
+// StreamNodeLogs follows a node container's logs through the workflow log
+// API. The stream ends with io.EOF once the container exits; cancel ctx or
+// call Close to stop earlier.
+func (c *Client) StreamNodeLogs(ctx context.Context, wf *Workflow, nodeID, container string) (*LogStream, error) {
+	if nodeID == "" {
+		return nil, fmt.Errorf("nodeID is empty")
+	}
+	if c.offline {
+		return nil, fmt.Errorf("live logs of %s: %w", nodeID, ErrNotCached)
+	}
+	query := url.Values{
+		"podName":              {wf.PodName(nodeID)},
+		"logOptions.container": {container},
+		"logOptions.follow":    {"true"},
+	}
+	resp, err := c.do(ctx, c.url(query, "api", "v1", "workflows", c.cfg.Namespace, wf.Metadata.Name, "log"))
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(resp.Body)
+	return &LogStream{body: resp.Body, next: func() (string, error) { return nextArgoLine(dec) }}, nil
+}
+
+// Next returns the next log line, or io.EOF at the end of the stream.
+func (s *LogStream) Next() (string, error) { return s.next() }
+
+// nextArgoLine decodes the workflow log API's stream of JSON results.
+func nextArgoLine(dec *json.Decoder) (string, error) {
+	for {
+		var entry struct {
+			Result *struct {
+				Content string `json:"content"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := dec.Decode(&entry); err != nil {
+			return "", err
+		}
+		if entry.Error != nil {
+			return "", fmt.Errorf("log stream: %s", entry.Error.Message)
+		}
+		if entry.Result != nil {
+			return entry.Result.Content, nil
+		}
+	}
+}
+
+func (s *LogStream) Close() error { return s.body.Close() }
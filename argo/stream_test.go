@@ -0,0 +1,71 @@
+package argo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestPodName(t *testing.T) {
+	wf := Workflow{
+		Metadata: ObjectMeta{Name: "sync-gj97n"},
+		Status: WorkflowStatus{Nodes: map[string]Node{
+			"sync-gj97n":            {Name: "sync-gj97n", Type: "Steps"},
+			"sync-gj97n-1234567890": {Name: "sync-gj97n[0].upload", TemplateName: "upload", Type: "Pod"},
+			"sync-gj97n-2345678901": {Name: "sync-gj97n[1].notify", TemplateRef: &TemplateRef{Name: "common", Template: "send-mail"}, Type: "Pod"},
+		}},
+	}
+	tests := []struct {
+		nodeID, format, want string
+	}{
+		{"sync-gj97n", "", "sync-gj97n"},
+		{"sync-gj97n-1234567890", "", "sync-gj97n-upload-2804518603"},
+		{"sync-gj97n-1234567890", "v1", "sync-gj97n-1234567890"},
+		{"sync-gj97n-2345678901", "", "sync-gj97n-send-mail-1357780644"},
+	}
+	for _, tt := range tests {
+		wf.Metadata.Annotations = map[string]string{podNameFormatAnnotation: tt.format}
+		if got := wf.PodName(tt.nodeID); got != tt.want {
+			t.Errorf("PodName(%q) with format %q = %q, want %q", tt.nodeID, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestStreamNodeLogs(t *testing.T) {
+	wf := &Workflow{
+		Metadata: ObjectMeta{Name: "sync-gj97n", Annotations: map[string]string{podNameFormatAnnotation: "v1"}},
+		Status:   WorkflowStatus{Nodes: map[string]Node{"sync-gj97n-2": {Name: "sync-gj97n[0].upload"}}},
+	}
+	c := newTestClient(t, map[string]http.HandlerFunc{
+		"/api/v1/workflows/cas/sync-gj97n/log": func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			if q.Get("podName") != "sync-gj97n-2" || q.Get("logOptions.follow") != "true" {
+				http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"result":{"content":"first","podName":"sync-gj97n-2"}}` + "\n"))
+			w.Write([]byte(`{"result":{"content":"second","podName":"sync-gj97n-2"}}` + "\n"))
+		},
+	})
+
+	stream, err := c.StreamNodeLogs(context.Background(), wf, "sync-gj97n-2", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	var lines []string
+	for {
+		line, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0] != "first" || lines[1] != "second" {
+		t.Errorf("lines = %q", lines)
+	}
+}
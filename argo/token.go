@@ -1,36 +1,206 @@
 package argo
 
 import (
+	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"strings"
+
+	"logviewer-tui/kube"
 )
 
-func getArgoToken() (string, error) {
-	// Try argo-server token first
-	token, err := getTokenFromSecret("argo-server.service-account-token")
-	if err == nil && token != "" {
-		return "Bearer " + token, nil
+// TokenProvider yields an Authorization header value for the Argo server.
+type TokenProvider interface {
+	Name() string
+	Token() (string, error)
+}
+
+// errNotConfigured marks a provider that was skipped rather than failed.
+var errNotConfigured = errors.New("not configured")
+
+// TokenChainError lists every provider that was tried and why it failed.
+type TokenChainError struct {
+	Attempts []TokenAttempt
+}
+
+// TokenAttempt is one provider's failure.
+type TokenAttempt struct {
+	Provider string
+	Err      error
+}
+
+func (e *TokenChainError) Error() string {
+	var b strings.Builder
+	b.WriteString("no Argo token available; tried:")
+	for _, a := range e.Attempts {
+		fmt.Fprintf(&b, "\n  • %s: %v", a.Provider, a.Err)
+	}
+	return b.String()
+}
+
+// TokenChain tries providers in order and returns the first token found.
+type TokenChain []TokenProvider
+
+func (c TokenChain) Name() string { return "chain" }
+
+func (c TokenChain) Token() (string, error) {
+	chainErr := &TokenChainError{}
+	for _, p := range c {
+		token, err := p.Token()
+		if err == nil && strings.TrimSpace(token) != "" {
+			return authHeader(token), nil
+		}
+		if err == nil {
+			err = errors.New("empty token")
+		}
+		chainErr.Attempts = append(chainErr.Attempts, TokenAttempt{Provider: p.Name(), Err: err})
+	}
+	return "", chainErr
+}
+
+// DefaultTokenChain is ARGO_TOKEN, --token, the token file, `argo auth
+// token`, the current kubeconfig user and finally the service-account
+// secrets in the workflow namespace.
+func DefaultTokenChain(cfg Config) TokenChain {
+	cfg = cfg.withDefaults()
+	return TokenChain{
+		EnvToken{Var: "ARGO_TOKEN"},
+		StaticToken{Label: "--token flag", Value: cfg.Token},
+		FileToken{Path: cfg.TokenFile},
+		ArgoCLIToken{},
+		KubeconfigToken{},
+		SecretToken{Namespace: cfg.Namespace, Names: []string{
+			"argo-server.service-account-token",     // argo-server token first
+			"argo-controller.service-account-token", // fallback for Local Env
+		}},
 	}
+}
+
+// authHeader adds the Bearer scheme unless the token already carries one,
+// as ARGO_TOKEN and `argo auth token` usually do.
+func authHeader(token string) string {
+	token = strings.TrimSpace(token)
+	if strings.HasPrefix(token, "Bearer ") || strings.HasPrefix(token, "Basic ") {
+		return token
+	}
+	return "Bearer " + token
+}
+
+// EnvToken reads a token from an environment variable.
+type EnvToken struct{ Var string }
 
-	// Fallback to argo-controller for Local Env
-	token, err = getTokenFromSecret("argo-controller.service-account-token")
-	if err == nil && token != "" {
-		return "Bearer " + token, nil
+func (p EnvToken) Name() string { return "$" + p.Var }
+
+func (p EnvToken) Token() (string, error) {
+	if v := os.Getenv(p.Var); v != "" {
+		return v, nil
 	}
+	return "", errNotConfigured
+}
+
+// StaticToken is a token given on the command line.
+type StaticToken struct{ Label, Value string }
+
+func (p StaticToken) Name() string { return p.Label }
 
-	return "", fmt.Errorf("failed to retrieve Argo token from argo-server and argo-controller")
+func (p StaticToken) Token() (string, error) {
+	if p.Value == "" {
+		return "", errNotConfigured
+	}
+	return p.Value, nil
 }
 
-func getTokenFromSecret(secretName string) (string, error) {
-	cmd := exec.Command("kubectl", "-n", "cas", "get", "secret", secretName, "-o", "jsonpath={.data.token}")
-	output, err := cmd.Output()
+// FileToken reads a token from a file, e.g. a mounted service-account token.
+type FileToken struct{ Path string }
+
+func (p FileToken) Name() string { return "token file" }
+
+func (p FileToken) Token() (string, error) {
+	if p.Path == "" {
+		return "", errNotConfigured
+	}
+	data, err := os.ReadFile(p.Path)
 	if err != nil {
 		return "", err
 	}
-	decoded, err := base64.StdEncoding.DecodeString(string(output))
+	return string(data), nil
+}
+
+// ArgoCLIToken asks the argo CLI, which knows its own login configuration.
+type ArgoCLIToken struct{}
+
+func (ArgoCLIToken) Name() string { return "argo auth token" }
+
+func (ArgoCLIToken) Token() (string, error) {
+	if _, err := exec.LookPath("argo"); err != nil {
+		return "", fmt.Errorf("argo CLI not found in PATH")
+	}
+	return runCommand(exec.Command("argo", "auth", "token"))
+}
+
+// KubeconfigToken uses the bearer token, token file or exec credential
+// plugin of the current kubeconfig user.
+type KubeconfigToken struct{}
+
+func (KubeconfigToken) Name() string { return "kubeconfig user" }
+
+func (KubeconfigToken) Token() (string, error) { return kube.UserToken() }
+
+// SecretToken reads service-account token secrets with kubectl, which needs
+// RBAC permission to read secrets in the namespace.
+type SecretToken struct {
+	Namespace string
+	Names     []string
+}
+
+func (p SecretToken) Name() string {
+	return fmt.Sprintf("secrets %s in namespace %s", strings.Join(p.Names, ", "), p.Namespace)
+}
+
+func (p SecretToken) Token() (string, error) {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return "", fmt.Errorf("kubectl not found in PATH")
+	}
+	var errs []string
+	for _, name := range p.Names {
+		token, err := getTokenFromSecret(p.Namespace, name)
+		if err == nil && token != "" {
+			return token, nil
+		}
+		if err == nil {
+			err = errors.New("empty token")
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+	}
+	return "", errors.New(strings.Join(errs, "; "))
+}
+
+func getTokenFromSecret(namespace, secretName string) (string, error) {
+	cmd := exec.Command("kubectl", "-n", namespace, "get", "secret", secretName, "-o", "jsonpath={.data.token}")
+	output, err := runCommand(cmd)
+	if err != nil {
+		return "", err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(output)
 	if err != nil {
 		return "", err
 	}
 	return string(decoded), nil
 }
+
+// runCommand returns stdout, or an error carrying the command's stderr.
+func runCommand(cmd *exec.Cmd) (string, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,150 @@
+package argo
+
+import (
+	"sort"
+	"time"
+)
+
+// TreeRow is one node of the workflow's node hierarchy, in display order.
+type TreeRow struct {
+	Node
+	Depth    int
+	Attempt  int // 1-based attempt number for the children of a Retry node
+	Attempts int // number of attempts, for Retry nodes
+}
+
+// Duration is how long the node ran, or has been running.
+func (n Node) Duration() time.Duration {
+	if n.StartedAt.IsZero() {
+		return 0
+	}
+	end := n.FinishedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(n.StartedAt)
+}
+
+// Duration is how long the workflow ran, or has been running.
+func (w Workflow) Duration() time.Duration {
+	if w.Status.StartedAt.IsZero() {
+		return 0
+	}
+	end := w.Status.FinishedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(w.Status.StartedAt)
+}
+
+// NodeTree flattens status.nodes into a depth-first tree. A node's parent is
+// the Retry node that lists it as a child, else its template boundary
+// (Steps/DAG), else any node listing it as a child. StepGroup nodes are
+// folded into their Steps node.
+func (w Workflow) NodeTree() []TreeRow {
+	nodes := w.Status.Nodes
+	retryParent := map[string]string{}
+	childOf := map[string]string{}
+	for id, n := range nodes {
+		for _, c := range n.Children {
+			if n.Type == "Retry" {
+				retryParent[c] = id
+			}
+			if _, ok := childOf[c]; !ok {
+				childOf[c] = id
+			}
+		}
+	}
+
+	parentOf := func(id string) string {
+		n := nodes[id]
+		if p, ok := retryParent[id]; ok {
+			return p
+		}
+		if n.BoundaryID != "" && n.BoundaryID != id {
+			if _, ok := nodes[n.BoundaryID]; ok {
+				return n.BoundaryID
+			}
+		}
+		p := childOf[id]
+		for p != "" && nodes[p].Type == "StepGroup" {
+			p = stepGroupOwner(nodes, childOf, p)
+		}
+		return p
+	}
+
+	children := map[string][]string{}
+	var roots []string
+	for id, n := range nodes {
+		if n.Type == "StepGroup" {
+			continue
+		}
+		if p := parentOf(id); p != "" {
+			children[p] = append(children[p], id)
+		} else {
+			roots = append(roots, id)
+		}
+	}
+
+	byStart := func(ids []string) {
+		sort.Slice(ids, func(i, j int) bool {
+			a, b := nodes[ids[i]], nodes[ids[j]]
+			if !a.StartedAt.Equal(b.StartedAt) {
+				return a.StartedAt.Before(b.StartedAt)
+			}
+			return a.DisplayName+ids[i] < b.DisplayName+ids[j]
+		})
+	}
+
+	var rows []TreeRow
+	visited := map[string]bool{}
+	var walk func(id string, depth, attempt int)
+	walk = func(id string, depth, attempt int) {
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+		n := nodes[id]
+		if n.ID == "" {
+			n.ID = id
+		}
+		kids := children[id]
+		byStart(kids)
+		row := TreeRow{Node: n, Depth: depth, Attempt: attempt}
+		if n.Type == "Retry" {
+			row.Attempts = len(kids)
+		}
+		rows = append(rows, row)
+		for i, c := range kids {
+			a := 0
+			if n.Type == "Retry" {
+				a = i + 1
+			}
+			walk(c, depth+1, a)
+		}
+	}
+	byStart(roots)
+	for _, id := range roots {
+		walk(id, 0, 0)
+	}
+	// Nodes caught in inconsistent parent links still get a row of their own.
+	var orphans []string
+	for id, n := range nodes {
+		if !visited[id] && n.Type != "StepGroup" {
+			orphans = append(orphans, id)
+		}
+	}
+	byStart(orphans)
+	for _, id := range orphans {
+		walk(id, 0, 0)
+	}
+	return rows
+}
+
+// stepGroupOwner returns the Steps node a StepGroup belongs to.
+func stepGroupOwner(nodes map[string]Node, childOf map[string]string, id string) string {
+	if b := nodes[id].BoundaryID; b != "" {
+		return b
+	}
+	return childOf[id]
+}
@@ -0,0 +1,52 @@
+package argo
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// A steps workflow whose second step is retried, calling a DAG whose two
+// tasks share a display name.
+const treeWorkflow = `{"metadata": {"name": "wf"}, "status": {"nodes": {
+  "wf":    {"id": "wf", "displayName": "wf", "type": "Steps", "phase": "Failed", "children": ["sg0"]},
+  "sg0":   {"id": "sg0", "displayName": "[0]", "type": "StepGroup", "boundaryID": "wf", "children": ["fetch", "retry"], "startedAt": "2025-03-14T10:00:00Z"},
+  "fetch": {"id": "fetch", "displayName": "fetch", "type": "Pod", "phase": "Succeeded", "boundaryID": "wf", "startedAt": "2025-03-14T10:00:00Z", "finishedAt": "2025-03-14T10:01:00Z"},
+  "retry": {"id": "retry", "displayName": "process", "type": "Retry", "phase": "Failed", "boundaryID": "wf", "children": ["try1", "try2"], "startedAt": "2025-03-14T10:00:01Z"},
+  "try1":  {"id": "try1", "displayName": "process(0)", "type": "Pod", "phase": "Failed", "boundaryID": "wf", "message": "exit code 1", "startedAt": "2025-03-14T10:00:01Z"},
+  "try2":  {"id": "try2", "displayName": "process(1)", "type": "DAG", "phase": "Failed", "boundaryID": "wf", "children": ["a"], "startedAt": "2025-03-14T10:02:00Z"},
+  "a":     {"id": "a", "displayName": "shard", "type": "Pod", "phase": "Succeeded", "boundaryID": "try2", "children": ["b"], "startedAt": "2025-03-14T10:02:00Z"},
+  "b":     {"id": "b", "displayName": "shard", "type": "Pod", "phase": "Failed", "boundaryID": "try2", "startedAt": "2025-03-14T10:03:00Z"}
+}}}`
+
+func TestNodeTree(t *testing.T) {
+	var wf Workflow
+	if err := json.Unmarshal([]byte(treeWorkflow), &wf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, row := range wf.NodeTree() {
+		line := strings.Repeat("  ", row.Depth) + row.ID
+		if row.Attempts > 0 {
+			line += " attempts=" + strconv.Itoa(row.Attempts)
+		}
+		if row.Attempt > 0 {
+			line += " attempt=" + strconv.Itoa(row.Attempt)
+		}
+		got = append(got, line)
+	}
+	want := []string{
+		"wf",
+		"  fetch",
+		"  retry attempts=2",
+		"    try1 attempt=1",
+		"    try2 attempt=2",
+		"      a",
+		"      b",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("NodeTree():\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,153 @@
+package argo
+
+import (
+	"sort"
+	"time"
+)
+
+// Workflow is the subset of an Argo Workflow the viewer uses.
+type Workflow struct {
+	Metadata ObjectMeta     `json:"metadata"`
+	Spec     WorkflowSpec   `json:"spec"`
+	Status   WorkflowStatus `json:"status"`
+	Archived bool           `json:"-"` // read from the workflow archive
+}
+
+type ObjectMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	UID               string            `json:"uid"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+}
+
+type WorkflowSpec struct {
+	Entrypoint string    `json:"entrypoint,omitempty"`
+	Arguments  Arguments `json:"arguments"`
+}
+
+// Arguments are the workflow's input parameters.
+type Arguments struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+}
+
+type Parameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type WorkflowStatus struct {
+	Phase      string          `json:"phase"`
+	Progress   string          `json:"progress,omitempty"` // e.g. "3/5" pods done
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt time.Time       `json:"finishedAt"`
+	Message    string          `json:"message,omitempty"`
+	Nodes      map[string]Node `json:"nodes,omitempty"`
+}
+
+// Node is one entry of status.nodes; only Pod nodes have logs.
+type Node struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	DisplayName  string       `json:"displayName"`
+	Type         string       `json:"type"`
+	Phase        string       `json:"phase"`
+	Message      string       `json:"message,omitempty"`
+	TemplateName string       `json:"templateName,omitempty"`
+	TemplateRef  *TemplateRef `json:"templateRef,omitempty"` // set instead of TemplateName for WorkflowTemplate steps
+	BoundaryID   string       `json:"boundaryID,omitempty"`
+	Children     []string     `json:"children,omitempty"`
+	StartedAt    time.Time    `json:"startedAt"`
+	FinishedAt   time.Time    `json:"finishedAt"`
+	Outputs      *Outputs     `json:"outputs,omitempty"`
+}
+
+// TemplateRef points a node at a template of a (Cluster)WorkflowTemplate.
+type TemplateRef struct {
+	Name         string `json:"name"`
+	Template     string `json:"template"`
+	ClusterScope bool   `json:"clusterScope,omitempty"`
+}
+
+// template is the name of the template the node ran, as the controller uses
+// it in pod names.
+func (n Node) template() string {
+	if n.TemplateRef != nil {
+		return n.TemplateRef.Template
+	}
+	return n.TemplateName
+}
+
+// Outputs lists a node's output parameters and artifacts, among them the
+// archived container logs ("main-logs", ...), and the main container's
+// result and exit code.
+type Outputs struct {
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Artifacts  []Artifact  `json:"artifacts,omitempty"`
+	Result     string      `json:"result,omitempty"`
+	ExitCode   string      `json:"exitCode,omitempty"`
+}
+
+type Artifact struct {
+	Name string            `json:"name"`
+	S3   *ArtifactLocation `json:"s3,omitempty"`
+	GCS  *ArtifactLocation `json:"gcs,omitempty"`
+}
+
+// ArtifactLocation is where the controller stored an artifact in its
+// repository's bucket.
+type ArtifactLocation struct {
+	Bucket string `json:"bucket,omitempty"`
+	Key    string `json:"key"`
+}
+
+// location is the artifact's place in a repository of type "s3" or "gcs".
+func (a Artifact) location(repoType string) *ArtifactLocation {
+	if repoType == "gcs" {
+		return a.GCS
+	}
+	return a.S3
+}
+
+// PodNodes returns the workflow's Pod nodes sorted by display name, then ID.
+func (w Workflow) PodNodes() []Node {
+	var pods []Node
+	for id, node := range w.Status.Nodes {
+		if node.Type == "Pod" {
+			if node.ID == "" {
+				node.ID = id
+			}
+			pods = append(pods, node)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].DisplayName != pods[j].DisplayName {
+			return pods[i].DisplayName < pods[j].DisplayName
+		}
+		return pods[i].ID < pods[j].ID
+	})
+	return pods
+}
+
+// FailedPods returns the Pod nodes that failed or errored, the first to
+// finish first, as that one usually holds the root cause.
+func (w Workflow) FailedPods() []Node {
+	var failed []Node
+	for _, node := range w.PodNodes() {
+		if node.Phase == "Failed" || node.Phase == "Error" {
+			failed = append(failed, node)
+		}
+	}
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failed[i].FinishedAt.Before(failed[j].FinishedAt)
+	})
+	return failed
+}
+
+// ListOptions filters ListWorkflows and ListArchived.
+type ListOptions struct {
+	LabelSelector string
+	NamePrefix    string // archived workflows only
+	Limit         int
+}
@@ -0,0 +1,360 @@
+package argo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"logviewer-tui/kube"
+)
+
+// GetWorkflow fetches a live workflow, nodes included. Offline, name may
+// also be the UID of a cached workflow.
+func (c *Client) GetWorkflow(ctx context.Context, name string) (*Workflow, error) {
+	if c.offline {
+		return c.cachedWorkflow(name)
+	}
+	var wf Workflow
+	if err := c.getJSON(ctx, c.url(nil, "api", "v1", "workflows", c.cfg.Namespace, name), &wf); err != nil {
+		return nil, err
+	}
+	c.cacheWorkflow(&wf)
+	return &wf, nil
+}
+
+// uidPattern matches Kubernetes UIDs such as 9f9aab90-319b-4655-905c-7ea2db0ef550.
+var uidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// FindWorkflow looks a workflow up by name or UID: a UID in the archive, a
+// name among the live workflows and then, once deleted from the cluster,
+// as the most recent archived workflow of that name.
+func (c *Client) FindWorkflow(ctx context.Context, nameOrUID string) (*Workflow, error) {
+	if c.offline {
+		return c.cachedWorkflow(nameOrUID)
+	}
+	if uidPattern.MatchString(nameOrUID) {
+		wf, err := c.GetArchivedWorkflow(ctx, nameOrUID)
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("no archived workflow has UID %s: %w", nameOrUID, err)
+		}
+		return wf, err
+	}
+	wf, err := c.GetWorkflow(ctx, nameOrUID)
+	if !errors.Is(err, ErrNotFound) {
+		return wf, err
+	}
+	archived, listErr := c.ListArchived(ctx, ListOptions{NamePrefix: nameOrUID})
+	if listErr != nil {
+		return nil, fmt.Errorf("%w; archive: %w", err, listErr)
+	}
+	var latest *Workflow
+	for i, a := range archived {
+		if a.Metadata.Name == nameOrUID && (latest == nil || a.Metadata.CreationTimestamp.After(latest.Metadata.CreationTimestamp)) {
+			latest = &archived[i]
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("workflow %s is neither live nor archived: %w", nameOrUID, err)
+	}
+	// Archive listings leave out the nodes.
+	return c.GetArchivedWorkflow(ctx, latest.Metadata.UID)
+}
+
+func (c *Client) cachedWorkflow(nameOrUID string) (*Workflow, error) {
+	if c.cache != nil {
+		if wf, ok := c.cache.Workflow(nameOrUID); ok {
+			return wf, nil
+		}
+	}
+	return nil, fmt.Errorf("workflow %s: %w", nameOrUID, ErrNotCached)
+}
+
+// cacheWorkflow stores a finished workflow; a cache that cannot be written
+// only costs a later download.
+func (c *Client) cacheWorkflow(wf *Workflow) {
+	if c.cache != nil {
+		c.cache.PutWorkflow(wf)
+	}
+}
+
+// ListNodes returns the Pod nodes of a live workflow.
+func (c *Client) ListNodes(ctx context.Context, name string) ([]Node, error) {
+	wf, err := c.GetWorkflow(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return wf.PodNodes(), nil
+}
+
+// ListWorkflows lists live workflows in the configured namespace; offline,
+// the cached ones.
+func (c *Client) ListWorkflows(ctx context.Context, opts ListOptions) ([]Workflow, error) {
+	if c.offline {
+		return c.listCached(opts)
+	}
+	query := url.Values{}
+	if opts.LabelSelector != "" {
+		query.Set("listOptions.labelSelector", opts.LabelSelector)
+	}
+	if opts.Limit > 0 {
+		query.Set("listOptions.limit", strconv.Itoa(opts.Limit))
+	}
+	var list struct {
+		Items []Workflow `json:"items"`
+	}
+	if err := c.getJSON(ctx, c.url(query, "api", "v1", "workflows", c.cfg.Namespace), &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListArchived lists archived workflows in the configured namespace. The
+// cache does not tell archived workflows apart, so offline it lists none.
+func (c *Client) ListArchived(ctx context.Context, opts ListOptions) ([]Workflow, error) {
+	if c.offline {
+		return nil, nil
+	}
+	query := url.Values{}
+	query.Set("namespace", c.cfg.Namespace)
+	if opts.LabelSelector != "" {
+		query.Set("listOptions.labelSelector", opts.LabelSelector)
+	}
+	if opts.Limit > 0 {
+		query.Set("listOptions.limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.NamePrefix != "" {
+		query.Set("namePrefix", opts.NamePrefix)
+	}
+	var list struct {
+		Items []Workflow `json:"items"`
+	}
+	if err := c.getJSON(ctx, c.url(query, "api", "v1", "archived-workflows"), &list); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		list.Items[i].Archived = true
+	}
+	return list.Items, nil
+}
+
+// listCached lists the cached workflows of the configured namespace that
+// match the options.
+func (c *Client) listCached(opts ListOptions) ([]Workflow, error) {
+	if c.cache == nil {
+		return nil, nil
+	}
+	cached, err := c.cache.List()
+	if err != nil {
+		return nil, err
+	}
+	var wfs []Workflow
+	for _, cw := range cached {
+		wf := cw.Workflow
+		if c.namespace(&wf) != c.cfg.Namespace || !strings.HasPrefix(wf.Metadata.Name, opts.NamePrefix) ||
+			!matchLabels(opts.LabelSelector, wf.Metadata.Labels) {
+			continue
+		}
+		wfs = append(wfs, wf)
+		if opts.Limit > 0 && len(wfs) == opts.Limit {
+			break
+		}
+	}
+	return wfs, nil
+}
+
+// matchLabels applies an equality-based label selector such as
+// "team=cas,env!=dev,owner" the way the server would.
+func matchLabels(selector string, labels map[string]string) bool {
+	for _, req := range strings.Split(selector, ",") {
+		req = strings.TrimSpace(req)
+		if k, v, ok := strings.Cut(req, "!="); ok {
+			if labels[strings.TrimSpace(k)] == strings.TrimSpace(v) {
+				return false
+			}
+			continue
+		}
+		k, v, ok := strings.Cut(strings.Replace(req, "==", "=", 1), "=")
+		got, present := labels[strings.TrimSpace(k)]
+		if req != "" && (!present || ok && got != strings.TrimSpace(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetNodeLogs downloads the logs of a node's main container.
+func (c *Client) GetNodeLogs(ctx context.Context, wf *Workflow, nodeID string) (string, error) {
+	return c.GetContainerLogs(ctx, wf, nodeID, "main")
+}
+
+// GetContainerLogs downloads the <container>-logs artifact of a node, falling
+// back to the archived-workflows path and then to the pod's own logs in
+// Kubernetes, for workflows without archive logging or whose artifacts were
+// collected. Archived workflows try the archived-workflows path first.
+func (c *Client) GetContainerLogs(ctx context.Context, wf *Workflow, nodeID, container string) (string, error) {
+	return c.DownloadContainerLogs(ctx, wf, nodeID, container, nil)
+}
+
+// DownloadContainerLogs is GetContainerLogs reporting each source's body to
+// progress as it arrives. Logs of completed nodes come from and go to the
+// cache, if any.
+func (c *Client) DownloadContainerLogs(ctx context.Context, wf *Workflow, nodeID, container string, progress Progress) (string, error) {
+	if nodeID == "" {
+		return "", fmt.Errorf("nodeID is empty")
+	}
+	if c.cache != nil {
+		if logs, ok := c.cache.Logs(wf, nodeID, container); ok {
+			if progress != nil && logs != "" {
+				progress([]byte(logs), int64(len(logs)), int64(len(logs)))
+			}
+			return logs, nil
+		}
+	}
+	if c.offline {
+		return "", fmt.Errorf("%s logs of %s: %w", container, nodeID, ErrNotCached)
+	}
+	logs, err := c.downloadContainerLogs(ctx, wf, nodeID, container, progress)
+	if err == nil && c.cache != nil {
+		c.cache.PutLogs(wf, nodeID, container, logs)
+	}
+	return logs, err
+}
+
+func (c *Client) downloadContainerLogs(ctx context.Context, wf *Workflow, nodeID, container string, progress Progress) (string, error) {
+	var bucketErr error
+	if c.bucket != nil {
+		logs, err := c.bucketLogs(ctx, wf, nodeID, container, progress)
+		if err == nil || ctx.Err() != nil {
+			return logs, err
+		}
+		bucketErr = err
+	}
+
+	artifact := container + "-logs"
+	archivedURL := c.url(nil, "artifact-files", c.cfg.ArtifactRepository, "archived-workflows", wf.Metadata.UID, nodeID, "outputs", artifact)
+	if wf.Archived {
+		// A live workflow of the same name has the same node IDs and pod
+		// names; only the archive path belongs to this run.
+		body, err := c.download(ctx, archivedURL, progress)
+		if err == nil {
+			return string(body), nil
+		}
+		if ctx.Err() != nil {
+			return "", err
+		}
+		return "", &LogFetchError{NodeID: nodeID, Container: container, Bucket: bucketErr, Primary: err}
+	}
+
+	primaryURL := c.url(nil, "artifact-files", c.cfg.ArtifactRepository, "workflows", wf.Metadata.Name, nodeID, "outputs", artifact)
+	body, primaryErr := c.download(ctx, primaryURL, progress)
+	if primaryErr == nil {
+		return string(body), nil
+	}
+	if ctx.Err() != nil {
+		return "", primaryErr
+	}
+
+	body, fallbackErr := c.download(ctx, archivedURL, progress)
+	if fallbackErr == nil {
+		return string(body), nil
+	}
+
+	logs, podErr := c.podLogs(ctx, wf, nodeID, container, progress)
+	if podErr == nil {
+		return logs, nil
+	}
+	return "", &LogFetchError{NodeID: nodeID, Container: container, Bucket: bucketErr, Primary: primaryErr, Fallback: fallbackErr, Pod: podErr}
+}
+
+// LogFetchError keeps why each log source of a node failed: the artifact
+// bucket when configured, the live artifact, the archived artifact and the
+// pod. Archived runs are only read from the archive, their Primary.
+type LogFetchError struct {
+	NodeID, Container string
+	Bucket            error // nil without Config.Artifacts
+	Primary           error
+	Fallback          error // nil for archived runs
+	Pod               error // nil for archived runs
+}
+
+func (e *LogFetchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to fetch %s logs of %s from every source:", e.Container, e.NodeID)
+	for _, source := range []struct {
+		name string
+		err  error
+	}{{"bucket", e.Bucket}, {"primary", e.Primary}, {"fallback", e.Fallback}, {"pod logs", e.Pod}} {
+		if source.err != nil {
+			fmt.Fprintf(&b, "\n• %s: %v", source.name, source.err)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap lets errors.Is and errors.As see every source's failure.
+func (e *LogFetchError) Unwrap() []error {
+	var errs []error
+	for _, err := range []error{e.Bucket, e.Primary, e.Fallback, e.Pod} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// podLogs reads a container log of the node's pod from Kubernetes.
+func (c *Client) podLogs(ctx context.Context, wf *Workflow, nodeID, container string, progress Progress) (string, error) {
+	k, err := c.kubeClient()
+	if err != nil {
+		return "", err
+	}
+	return k.DownloadPodLogs(ctx, c.namespace(wf), wf.PodName(nodeID), container, kube.Progress(progress))
+}
+
+// NodeContainers lists the containers of a node's pod whose logs can be
+// read: init containers, then main, wait and sidecars as Kubernetes reports
+// them. When the pod is gone, the node's log artifacts stand in.
+func (c *Client) NodeContainers(ctx context.Context, wf *Workflow, nodeID string) []string {
+	var names []string
+	if k, err := c.kubeClient(); err == nil && !c.offline {
+		names, _ = k.PodContainers(ctx, c.namespace(wf), wf.PodName(nodeID))
+	}
+	if outputs := wf.Status.Nodes[nodeID].Outputs; outputs != nil {
+		for _, a := range outputs.Artifacts {
+			if name, ok := strings.CutSuffix(a.Name, "-logs"); ok && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	if !slices.Contains(names, "main") {
+		names = append(names, "main")
+	}
+	return names
+}
+
+func (c *Client) namespace(wf *Workflow) string {
+	if wf.Metadata.Namespace != "" {
+		return wf.Metadata.Namespace
+	}
+	return c.cfg.Namespace
+}
+
+// GetArchivedWorkflow fetches a workflow from the archive by UID.
+func (c *Client) GetArchivedWorkflow(ctx context.Context, uid string) (*Workflow, error) {
+	if c.offline {
+		return c.cachedWorkflow(uid)
+	}
+	var wf Workflow
+	query := url.Values{"namespace": {c.cfg.Namespace}}
+	if err := c.getJSON(ctx, c.url(query, "api", "v1", "archived-workflows", uid), &wf); err != nil {
+		return nil, err
+	}
+	wf.Archived = true
+	c.cacheWorkflow(&wf)
+	return &wf, nil
+}
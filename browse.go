@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"logviewer-tui/argo"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// browseSession is the workflow browser: the live and archived runs
+// matching a filter query, narrowed further by a name search.
+type browseSession struct {
+	client    *argo.Client
+	query     textinput.Model // see argo.ParseWorkflowFilter
+	search    textinput.Model
+	searching bool // modeBrowseEdit edits search rather than query
+	rows      []argo.BrowsedWorkflow
+	cursor    int // into shown()
+	status    string
+
+	// pendingFetch is the pending listing, ended by the cancel key or the
+	// next listing.
+	pendingFetch
+}
+
+type workflowsLoadedMsg struct {
+	fetch context.Context
+	rows  []argo.BrowsedWorkflow
+	err   error
+}
+
+// openBrowser starts the browser on the workflows matching query.
+func (m *model) openBrowser(client *argo.Client, query string) {
+	m.browse = browseSession{
+		client: client,
+		query:  m.newInput("filter> ", "name-prefix label:team=cas phase:Failed since:12h until:2025-03-14"),
+		search: m.newInput("/", "name"),
+	}
+	m.browse.query.SetValue(query)
+	m.mode = modeBrowse
+	m.browse.status = "⏳ Loading workflows…"
+	m.browse.start()
+}
+
+// newInput returns a one-line text input styled after the theme.
+func (m model) newInput(prompt, placeholder string) textinput.Model {
+	in := textinput.New()
+	in.Prompt = prompt
+	in.Placeholder = placeholder
+	in.PromptStyle = m.theme.Key
+	in.TextStyle = m.theme.Text
+	in.PlaceholderStyle = m.theme.Hint
+	return in
+}
+
+func (m model) loadWorkflows() tea.Cmd {
+	client, ctx, query := m.browse.client, m.browse.ctx, m.browse.query.Value()
+	return func() tea.Msg {
+		rows, err := client.BrowseWorkflows(ctx, query)
+		return workflowsLoadedMsg{fetch: ctx, rows: rows, err: err}
+	}
+}
+
+// shown returns the rows whose name contains the search.
+func (b browseSession) shown() []argo.BrowsedWorkflow {
+	search := strings.ToLower(b.search.Value())
+	if search == "" {
+		return b.rows
+	}
+	var rows []argo.BrowsedWorkflow
+	for _, row := range b.rows {
+		if strings.Contains(strings.ToLower(row.Metadata.Name), search) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// pickWorkflow leaves the browser for the steps of wf, keeping the
+// --compare baseline.
+func (m *model) pickWorkflow(wf argo.BrowsedWorkflow) tea.Cmd {
+	m.stopStreams()
+	m.session.end()
+	compareName, compare := m.session.compareName, m.session.compare
+	m.openWorkflow(m.browse.client, wf.Metadata.Name, nil)
+	m.session.compareName, m.session.compare = compareName, compare
+
+	m.session.status = "⏳ Loading workflow " + wf.Metadata.Name + "…"
+	client, ctx := m.session.client, m.session.ctx
+	load := func() tea.Msg {
+		full, err := wf.Load(ctx, client)
+		return workflowLoadedMsg{fetch: ctx, wf: full, err: err}
+	}
+	if compareName != "" && compare == nil {
+		return tea.Batch(load, m.loadCompare())
+	}
+	return load
+}
+
+func (m model) updateBrowse(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case workflowsLoadedMsg:
+		if msg.fetch != m.browse.ctx {
+			return m, nil
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.browse.status = "⏹ Cancelled."
+			return m, nil
+		}
+		m.browse.end()
+		var archiveErr *argo.ArchiveError
+		if msg.err != nil && !errors.As(msg.err, &archiveErr) {
+			m.browse.status = "❌ Failed to list workflows: " + msg.err.Error()
+			return m, nil
+		}
+		m.browse.rows = msg.rows
+		m.browse.cursor = 0
+		switch {
+		case archiveErr != nil:
+			m.browse.status = "⚠️ Only live workflows listed: " + archiveErr.Error()
+		case len(msg.rows) == 0:
+			m.browse.status = "No workflows match the filter"
+		default:
+			m.browse.status = ""
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		k := m.keys.Browse
+		if m.browse.pending() && key.Matches(msg, k.Cancel) {
+			m.browse.end()
+			m.browse.status = "⏹ Cancelling…"
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, k.Up):
+			if m.browse.cursor > 0 {
+				m.browse.cursor--
+			}
+		case key.Matches(msg, k.Down):
+			if m.browse.cursor < len(m.browse.shown())-1 {
+				m.browse.cursor++
+			}
+		case key.Matches(msg, k.Open):
+			if rows := m.browse.shown(); len(rows) > 0 {
+				return m, m.pickWorkflow(rows[m.browse.cursor])
+			}
+		case key.Matches(msg, k.Filter, k.Search):
+			m.browse.searching = key.Matches(msg, k.Search)
+			m.mode = modeBrowseEdit
+			return m, m.browse.input().Focus()
+		case key.Matches(msg, k.Reload):
+			m.browse.start()
+			m.browse.status = "⏳ Loading workflows…"
+			return m, m.loadWorkflows()
+		case key.Matches(msg, k.Back):
+			if m.session.client != nil {
+				m.mode = modeSteps
+			}
+		case key.Matches(msg, k.Quit):
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// input is the text input modeBrowseEdit edits.
+func (b *browseSession) input() *textinput.Model {
+	if b.searching {
+		return &b.search
+	}
+	return &b.query
+}
+
+// updateBrowseEdit edits the filter, which reloads the workflows once
+// applied, or the name search, which narrows them as it is typed.
+func (m model) updateBrowseEdit(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		k := m.keys.BrowseEdit
+		switch {
+		case key.Matches(msg, k.Apply):
+			m.browse.input().Blur()
+			m.mode = modeBrowse
+			if m.browse.searching {
+				return m, nil
+			}
+			m.browse.start()
+			m.browse.status = "⏳ Loading workflows…"
+			return m, m.loadWorkflows()
+		case key.Matches(msg, k.Cancel):
+			m.browse.input().Blur()
+			if m.browse.searching {
+				m.browse.search.SetValue("")
+				m.browse.cursor = 0
+			}
+			m.mode = modeBrowse
+			return m, nil
+		}
+	}
+	in := m.browse.input()
+	var cmd tea.Cmd
+	*in, cmd = in.Update(msg)
+	if m.browse.searching {
+		m.browse.cursor = min(m.browse.cursor, max(0, len(m.browse.shown())-1))
+	}
+	return m, cmd
+}
+
+// viewBrowse renders the browser, one workflow per line.
+func (m model) viewBrowse(footer string) string {
+	rows := m.browse.shown()
+	title := fmt.Sprintf("🔎 Workflows (%d)", len(rows))
+
+	query := m.browse.query.View()
+	if m.mode != modeBrowseEdit || m.browse.searching {
+		query = m.theme.Hint.Render("filter: " + m.browse.query.Value())
+	}
+	if m.browse.searching || m.browse.search.Value() != "" {
+		query += "\n" + m.browse.search.View()
+	}
+
+	visible := max(1, m.height-4)
+	start := 0
+	if m.browse.cursor >= visible {
+		start = m.browse.cursor - visible + 1
+	}
+	var b strings.Builder
+	for i := start; i < len(rows) && i < start+visible; i++ {
+		prefix := "  "
+		if i == m.browse.cursor {
+			prefix = "> "
+		}
+		wf := rows[i]
+		icon := argo.PhaseIcon(wf.Status.Phase)
+		if wf.Status.Phase == "Failed" || wf.Status.Phase == "Error" {
+			icon = m.theme.level("ERROR").Render(icon)
+		}
+		fmt.Fprintf(&b, "%s%s %-45s %s\n", prefix, icon, wf.Metadata.Name, m.theme.Hint.Render(wf.Description()))
+	}
+	status := ""
+	if m.browse.status != "" {
+		status = m.browse.status + "\n"
+	}
+	return m.theme.Title.Render(title) + "\n" + query + "\n\n" + b.String() + "\n" + status + footer
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"logviewer-tui/argo"
+)
+
+// cacheCommand runs "cache list" or "cache clear [workflow]" and returns the
+// exit code.
+func cacheCommand(cache *argo.Cache, args []string) int {
+	if cache == nil {
+		fmt.Println("The cache is disabled (cache.maxSizeMB is 0).")
+		return 0
+	}
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		list, err := cache.List()
+		if err != nil {
+			fmt.Println("❌ Failed to read the cache:", err)
+			return 1
+		}
+		if len(list) == 0 {
+			fmt.Println("The cache in", cache.Dir(), "is empty.")
+			return 0
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "WORKFLOW\tNAMESPACE\tUID\tPHASE\tLOGS\tSIZE\tLAST USED")
+		var total int64
+		for _, cw := range list {
+			name := cw.Metadata.Name
+			if name == "" {
+				name = "(logs only)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", name, cw.Metadata.Namespace, cw.Metadata.UID,
+				cw.Status.Phase, cw.Logs, formatBytes(cw.Size), cw.LastUsed.Format(time.DateTime))
+			total += cw.Size
+		}
+		w.Flush()
+		fmt.Printf("\n%d workflows, %s in %s\n", len(list), formatBytes(total), cache.Dir())
+		return 0
+
+	case len(args) >= 1 && len(args) <= 2 && args[0] == "clear":
+		target := ""
+		if len(args) == 2 {
+			target = args[1]
+		}
+		n, err := cache.Clear(target)
+		if err != nil {
+			fmt.Println("❌ Failed to clear the cache:", err)
+			return 1
+		}
+		fmt.Printf("🧹 Removed %d cached workflows.\n", n)
+		return 0
+	}
+	fmt.Println("Usage: logviewer cache list | cache clear [workflow name or UID]")
+	return 2
+}
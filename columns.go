@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// column describes one cell of a list line. Field is either one of the
+// built-in names (timestamp, level, message, step, container, pod) or a dot-separated path into
+// the raw log object, e.g. "traceId" or "context.user.id".
+type column struct {
+	Field string `json:"field"`
+	Width int    `json:"width,omitempty"` // 0 = fill the remaining width
+}
+
+var defaultColumns = []column{
+	{Field: "timestamp", Width: 24},
+	{Field: "level", Width: 7}, // WARNING; widened to longer levels in the logs
+	{Field: "message"},
+}
+
+const ellipsis = "…"
+
+// levelWidth is the width of the longest level in the logs.
+func (m model) levelWidth() int {
+	width := 0
+	for _, log := range m.logs {
+		width = max(width, lipgloss.Width(log.fieldValue("level")))
+	}
+	return width
+}
+
+// fieldValue resolves a column field against a log entry.
+func (l logEntry) fieldValue(field string) string {
+	switch field {
+	case "timestamp":
+		return l.Timestamp
+	case "level":
+		return strings.ToUpper(l.Level)
+	case "message":
+		return l.Message
+	case "step":
+		return l.Step
+	case "container":
+		return l.Container
+	case "pod":
+		return l.Pod
+	}
+
+	var cur interface{} = l.Fields
+	for _, part := range strings.Split(field, ".") {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		if cur, ok = obj[part]; !ok {
+			return ""
+		}
+	}
+	switch v := cur.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// fitCell truncates s with an ellipsis or pads it so it is exactly width wide.
+func fitCell(s string, width int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if width <= 0 {
+		return s
+	}
+	if lipgloss.Width(s) > width {
+		runes := []rune(s)
+		for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+			runes = runes[:len(runes)-1]
+		}
+		return string(runes) + ellipsis
+	}
+	return s + strings.Repeat(" ", width-lipgloss.Width(s))
+}
+
+// renderColumns renders the cells of a list line. avail is the width left for
+// the line; a zero-width column takes whatever remains of it.
+func renderColumns(log logEntry, columns []column, avail int) []string {
+	cells := make([]string, 0, len(columns))
+	used := 0
+	for i, col := range columns {
+		width := col.Width
+		if width <= 0 && avail > 0 {
+			width = max(1, avail-used)
+		}
+		cell := fitCell(log.fieldValue(col.Field), width)
+		if col.Width <= 0 {
+			// Flexible columns are not padded, only truncated.
+			cell = strings.TrimRight(cell, " ")
+		}
+		if i < len(columns)-1 {
+			cell += " "
+		}
+		used += lipgloss.Width(cell)
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// availableFields lists the built-in fields followed by every leaf path seen
+// in the loaded logs, so hidden fields like traceId can be picked as columns.
+func availableFields(logs []logEntry) []string {
+	seen := map[string]bool{}
+	var paths []string
+	var walk func(prefix string, obj map[string]interface{}, depth int)
+	walk = func(prefix string, obj map[string]interface{}, depth int) {
+		for k, v := range obj {
+			path := prefix + k
+			if nested, ok := v.(map[string]interface{}); ok && depth < 3 {
+				walk(path+".", nested, depth+1)
+				continue
+			}
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	hasStep, hasPod := false, false
+	for _, log := range logs {
+		walk("", log.Fields, 0)
+		hasStep = hasStep || log.Step != ""
+		hasPod = hasPod || log.Pod != ""
+	}
+	sort.Strings(paths)
+
+	fields := []string{"timestamp", "level", "message"}
+	if hasStep {
+		fields = append(fields, "step", "container")
+	}
+	if hasPod {
+		fields = append(fields, "pod", "container")
+	}
+	for _, p := range paths {
+		switch p {
+		case "timestamp", "level", "message", "step", "container", "pod":
+			continue
+		}
+		fields = append(fields, p)
+	}
+	return fields
+}
+
+// pickerItem is one row of the column picker.
+type pickerItem struct {
+	column
+	Enabled bool
+}
+
+func newPickerItems(columns []column, logs []logEntry) []pickerItem {
+	var items []pickerItem
+	inLayout := map[string]bool{}
+	for _, col := range columns {
+		items = append(items, pickerItem{column: col, Enabled: true})
+		inLayout[col.Field] = true
+	}
+	for _, field := range availableFields(logs) {
+		if !inLayout[field] {
+			items = append(items, pickerItem{column: column{Field: field, Width: 16}})
+		}
+	}
+	return items
+}
+
+func pickerColumns(items []pickerItem) []column {
+	var columns []column
+	for _, it := range items {
+		if it.Enabled {
+			columns = append(columns, it.column)
+		}
+	}
+	return columns
+}
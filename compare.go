@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"logviewer-tui/argo"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// compareState is the comparison of one step between the opened workflow
+// and a baseline run, entries grouped by message template.
+type compareState struct {
+	step     string
+	rows     []compareRow
+	base     runStats
+	this     runStats
+	cursor   int
+	diffOnly bool // hide templates seen equally often in both runs
+}
+
+// compareRow counts one level and message template in both runs. first* is
+// the offset of the first occurrence from the run's first entry, -1 when
+// the run has no such entry or no usable timestamps.
+type compareRow struct {
+	level, template      string
+	baseCount, thisCount int
+	baseFirst, thisFirst time.Duration
+}
+
+type runStats struct {
+	entries  int
+	duration time.Duration
+}
+
+type compareLoadedMsg struct {
+	wf  *argo.Workflow
+	err error
+}
+
+type compareLogsMsg struct {
+	fetch      context.Context
+	step       string
+	base, this string
+	err        error
+}
+
+// templatePatterns replace the variable parts of a message, most specific
+// first. A pattern with only set replaces just the matches it accepts.
+var templatePatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+	only func(string) bool
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<time>", nil},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>", nil},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), "<ip>", nil},
+	// Hashes and IDs are long and mix digits and letters, unlike words such
+	// as "e2e" or "add1".
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{7,}\b`), "<hex>", func(s string) bool {
+		return strings.ContainsAny(s, "0123456789") && strings.ContainsAny(strings.ToLower(s), "abcdef")
+	}},
+	{regexp.MustCompile(`"[^"]*"|'[^']*'`), "<str>", nil},
+	{regexp.MustCompile(`-?\b\d+(\.\d+)?`), "<n>", nil},
+	{regexp.MustCompile(`\s+`), " ", nil},
+}
+
+// messageTemplate normalizes a message so that runs of the same code line
+// compare equal: timestamps, IDs, addresses, quoted values and numbers
+// become placeholders.
+func messageTemplate(msg string) string {
+	for _, p := range templatePatterns {
+		msg = p.re.ReplaceAllStringFunc(msg, func(match string) string {
+			if p.only != nil && !p.only(match) {
+				return match
+			}
+			return p.repl
+		})
+	}
+	return strings.TrimSpace(msg)
+}
+
+// compareRuns groups both runs' entries by level and template, in order of
+// first appearance in this run, then the baseline.
+func compareRuns(base, this []logEntry) ([]compareRow, runStats, runStats) {
+	var rows []compareRow
+	index := map[string]int{}
+	add := func(logs []logEntry, count func(*compareRow) (*int, *time.Duration)) runStats {
+		start, end, timed := runSpan(logs)
+		for _, log := range logs {
+			k := strings.ToUpper(log.Level) + "\x00" + messageTemplate(log.Message)
+			i, ok := index[k]
+			if !ok {
+				i = len(rows)
+				index[k] = i
+				rows = append(rows, compareRow{
+					level: strings.ToUpper(log.Level), template: messageTemplate(log.Message),
+					baseFirst: -1, thisFirst: -1,
+				})
+			}
+			n, first := count(&rows[i])
+			*n++
+			if t, err := time.Parse(time.RFC3339Nano, log.Timestamp); err == nil && timed && *first < 0 {
+				*first = t.Sub(start)
+			}
+		}
+		return runStats{entries: len(logs), duration: end.Sub(start)}
+	}
+	thisStats := add(this, func(r *compareRow) (*int, *time.Duration) { return &r.thisCount, &r.thisFirst })
+	baseStats := add(base, func(r *compareRow) (*int, *time.Duration) { return &r.baseCount, &r.baseFirst })
+	return rows, baseStats, thisStats
+}
+
+// runSpan is the first and last timestamp of a run's entries.
+func runSpan(logs []logEntry) (start, end time.Time, ok bool) {
+	for _, log := range logs {
+		t, err := time.Parse(time.RFC3339Nano, log.Timestamp)
+		if err != nil {
+			continue
+		}
+		if !ok || t.Before(start) {
+			start = t
+		}
+		if !ok || t.After(end) {
+			end = t
+		}
+		ok = true
+	}
+	return start, end, ok
+}
+
+// relativeName is a node's path within its workflow, e.g. "[1].upload",
+// which stays the same between runs of one template.
+func relativeName(wf *argo.Workflow, node argo.Node) string {
+	return strings.TrimPrefix(node.Name, wf.Metadata.Name)
+}
+
+// matchingNode finds the node of another run of the same template that ran
+// the given step: same path, else the first pod of the same display name.
+func matchingNode(from *argo.Workflow, nodeID string, to *argo.Workflow) (string, bool) {
+	node := from.Status.Nodes[nodeID]
+	var byName string
+	for _, other := range to.PodNodes() {
+		if relativeName(to, other) == relativeName(from, node) {
+			return other.ID, true
+		}
+		if byName == "" && other.DisplayName == node.DisplayName {
+			byName = other.ID
+		}
+	}
+	return byName, byName != ""
+}
+
+func (m model) loadCompare() tea.Cmd {
+	client, name := m.session.client, m.session.compareName
+	return func() tea.Msg {
+		wf, err := client.FindWorkflow(context.Background(), name)
+		return compareLoadedMsg{wf: wf, err: err}
+	}
+}
+
+// loadComparison fetches the main logs of a step from both runs.
+func (m *model) loadComparison(nodeID string) tea.Cmd {
+	baseID, ok := matchingNode(m.session.wf, nodeID, m.session.compare)
+	step := m.session.wf.Status.Nodes[nodeID].DisplayName
+	if !ok {
+		m.session.status = "⚠️ " + m.session.compare.Metadata.Name + " has no step " + step
+		return nil
+	}
+	m.session.status = "📡 Fetching " + step + " from both runs…"
+	m.session.start()
+	client, ctx := m.session.client, m.session.ctx
+	this, base := m.session.wf, m.session.compare
+	return func() tea.Msg {
+		var wg sync.WaitGroup
+		var thisLogs, baseLogs string
+		var thisErr, baseErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			thisLogs, thisErr = client.GetContainerLogs(ctx, this, nodeID, "main")
+		}()
+		go func() {
+			defer wg.Done()
+			baseLogs, baseErr = client.GetContainerLogs(ctx, base, baseID, "main")
+		}()
+		wg.Wait()
+		msg := compareLogsMsg{fetch: ctx, step: step, base: baseLogs, this: thisLogs}
+		switch {
+		case thisErr != nil:
+			msg.err = fmt.Errorf("%s: %w", this.Metadata.Name, thisErr)
+		case baseErr != nil:
+			msg.err = fmt.Errorf("%s: %w", base.Metadata.Name, baseErr)
+		}
+		return msg
+	}
+}
+
+func (m model) updateCompare(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case compareLoadedMsg:
+		if msg.err != nil {
+			m.session.compareErr = msg.err
+			m.session.status = "❌ Failed to fetch " + m.session.compareName + " for comparison: " + msg.err.Error()
+			return m, nil
+		}
+		m.session.compare = msg.wf
+		return m, nil
+
+	case compareLogsMsg:
+		if msg.fetch != m.session.ctx {
+			return m, nil
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.session.status = "⏹ Cancelled."
+			return m, nil
+		}
+		m.session.end()
+		if msg.err != nil {
+			m.session.status = "❌ Failed to fetch logs to compare: " + msg.err.Error()
+			return m, nil
+		}
+		rows, base, this := compareRuns(parseLogs(msg.base, m.fields), parseLogs(msg.this, m.fields))
+		m.session.comparison = compareState{step: msg.step, rows: rows, base: base, this: this}
+		m.session.status = ""
+		m.mode = modeCompare
+		return m, nil
+
+	case tea.KeyMsg:
+		c := &m.session.comparison
+		k := m.keys.Compare
+		switch {
+		case key.Matches(msg, k.Up):
+			if c.cursor > 0 {
+				c.cursor--
+			}
+		case key.Matches(msg, k.Down):
+			if c.cursor < len(c.visibleRows())-1 {
+				c.cursor++
+			}
+		case key.Matches(msg, k.DiffOnly):
+			c.diffOnly = !c.diffOnly
+			c.cursor = 0
+		case key.Matches(msg, k.Back):
+			m.mode = modeSteps
+		}
+	}
+	return m, nil
+}
+
+func (c compareState) visibleRows() []compareRow {
+	if !c.diffOnly {
+		return c.rows
+	}
+	var rows []compareRow
+	for _, r := range c.rows {
+		if r.baseCount != r.thisCount {
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// viewCompare renders the comparison table: counts in both runs, the shift
+// of the first occurrence, and each template marked by how it differs.
+func (m model) viewCompare(footer string) string {
+	c := m.session.comparison
+	title := m.theme.Title.Render(fmt.Sprintf("⚖️  Compare — %s: %s vs %s",
+		c.step, m.session.wf.Metadata.Name, m.session.compare.Metadata.Name))
+	stats := fmt.Sprintf("this run %d entries over %s · baseline %d entries over %s",
+		c.this.entries, argo.FormatDuration(c.this.duration), c.base.entries, argo.FormatDuration(c.base.duration))
+	if c.this.duration > 0 && c.base.duration > 0 {
+		stats += " (" + signedDuration(c.this.duration-c.base.duration) + ")"
+	}
+	legend := m.theme.Hint.Render("+ only in this run · − only in the baseline · ≠ count differs")
+
+	rows := c.visibleRows()
+	header := fmt.Sprintf("  %-1s %6s %6s %9s  %-5s  %s", "", "this", "base", "Δ first", "LEVEL", "MESSAGE")
+	var b strings.Builder
+	visible := max(1, m.height-8)
+	start := 0
+	if c.cursor >= visible {
+		start = c.cursor - visible + 1
+	}
+	for i := start; i < len(rows) && i < start+visible; i++ {
+		r := rows[i]
+		mark, style := " ", m.theme.Text
+		switch {
+		case r.baseCount == 0:
+			mark, style = "+", m.theme.level("ERROR")
+		case r.thisCount == 0:
+			mark, style = "−", m.theme.level("WARN")
+		case r.baseCount != r.thisCount:
+			mark = "≠"
+		}
+		shift := ""
+		if r.baseFirst >= 0 && r.thisFirst >= 0 {
+			shift = signedDuration(r.thisFirst - r.baseFirst)
+		}
+		prefix := "  "
+		if i == c.cursor {
+			prefix = "> "
+		}
+		line := fmt.Sprintf("%s %6d %6d %9s  %-5s  %s", mark, r.thisCount, r.baseCount, shift, r.level, r.template)
+		if m.width > 2 && len([]rune(line)) > m.width-2 {
+			line = string([]rune(line)[:m.width-3]) + "…"
+		}
+		b.WriteString(prefix + style.Render(line) + "\n")
+	}
+	if len(rows) == 0 {
+		b.WriteString(m.theme.Hint.Render("  (no differences)") + "\n")
+	}
+	return title + "\n" + stats + "\n" + legend + "\n\n" + m.theme.Hint.Render(header) + "\n" + b.String() + "\n" + footer
+}
+
+// signedDuration renders a time difference with its sign, e.g. +1.2s.
+func signedDuration(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Second {
+		return sign + d.Round(time.Millisecond).String()
+	}
+	return sign + argo.FormatDuration(d)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMessageTemplate(t *testing.T) {
+	tests := []struct {
+		msg, want string
+	}{
+		{"Processed 42 items in 1.5s", "Processed <n> items in <n>s"},
+		{"Retry -3 of 5", "Retry <n> of <n>"},
+		{"Started at 2025-03-13T16:05:36.013Z", "Started at <time>"},
+		{"Started at 2025-03-13 16:05:36+01:00", "Started at <time>"},
+		{"Job 9f9aab90-319b-4655-905c-7ea2db0ef550 done", "Job <uuid> done"},
+		{"Connected to 10.0.12.7:5432", "Connected to <ip>"},
+		{"Commit 3f2c9e1 and object 5f1d2a7c8b9e", "Commit <hex> and object <hex>"},
+		{`User "alice" opened 'report.pdf'`, "User <str> opened <str>"},
+		{"Ran e2e suite after add1 on a1", "Ran e2e suite after add1 on a1"},
+		{"Cafe deadbeef facade", "Cafe deadbeef facade"},
+		{"  too   many\tspaces ", "too many spaces"},
+	}
+	for _, tt := range tests {
+		if got := messageTemplate(tt.msg); got != tt.want {
+			t.Errorf("messageTemplate(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	base := []logEntry{
+		{Level: "info", Timestamp: "2025-03-13T10:00:00Z", Message: "Started job 1"},
+		{Level: "INFO", Timestamp: "2025-03-13T10:00:02Z", Message: "Fetched 10 rows"},
+		{Level: "WARN", Timestamp: "2025-03-13T10:00:03Z", Message: "Slow query"},
+		{Level: "INFO", Timestamp: "2025-03-13T10:00:04Z", Message: "Fetched 12 rows"},
+	}
+	this := []logEntry{
+		{Level: "INFO", Timestamp: "2025-03-14T10:00:00Z", Message: "Started job 2"},
+		{Level: "ERROR", Timestamp: "2025-03-14T10:00:05Z", Message: "Connection reset"},
+		{Level: "INFO", Timestamp: "2025-03-14T10:00:07Z", Message: "Fetched 3 rows"},
+	}
+	rows, baseStats, thisStats := compareRuns(base, this)
+
+	want := []compareRow{
+		{level: "INFO", template: "Started job <n>", baseCount: 1, thisCount: 1, baseFirst: 0, thisFirst: 0},
+		{level: "ERROR", template: "Connection reset", baseCount: 0, thisCount: 1, baseFirst: -1, thisFirst: 5 * time.Second},
+		{level: "INFO", template: "Fetched <n> rows", baseCount: 2, thisCount: 1, baseFirst: 2 * time.Second, thisFirst: 7 * time.Second},
+		{level: "WARN", template: "Slow query", baseCount: 1, thisCount: 0, baseFirst: 3 * time.Second, thisFirst: -1},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("compareRuns() = %d rows %+v, want %d", len(rows), rows, len(want))
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+	if baseStats != (runStats{entries: 4, duration: 4 * time.Second}) {
+		t.Errorf("base stats = %+v", baseStats)
+	}
+	if thisStats != (runStats{entries: 3, duration: 7 * time.Second}) {
+		t.Errorf("this stats = %+v", thisStats)
+	}
+}
+
+func TestCompareRunsWithoutTimestamps(t *testing.T) {
+	rows, _, _ := compareRuns(nil, []logEntry{{Level: "INFO", Timestamp: "<nil>", Message: "hello"}})
+	if len(rows) != 1 || rows[0].thisCount != 1 || rows[0].thisFirst != -1 || rows[0].baseFirst != -1 {
+		t.Errorf("compareRuns() = %+v", rows)
+	}
+}
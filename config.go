@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"logviewer-tui/argo"
+)
+
+// config is the on-disk configuration, read from configPath() or --config.
+type config struct {
+	Filters filterConfig           `json:"filters"`
+	Columns []column               `json:"columns,omitempty"`
+	Fields  fieldMapping           `json:"fields"`
+	Theme   string                 `json:"theme,omitempty"`  // built-in or one of Themes
+	Themes  map[string]themeConfig `json:"themes,omitempty"` // user-defined themes
+	Colors  map[string]string      `json:"colors,omitempty"` // level -> color, over the theme
+	Keys    map[string][]string    `json:"keys,omitempty"`   // action -> keys
+	Argo    argo.Config            `json:"argo"`
+	Cache   cacheConfig            `json:"cache"`
+}
+
+type filterConfig struct {
+	Level   string   `json:"level,omitempty"`   // ERROR, WARN, INFO or DEBUG
+	Exclude []string `json:"exclude,omitempty"` // regexes, as typed after r
+}
+
+// cacheConfig sets where fetched workflows and logs are kept and how much
+// disk they may use.
+type cacheConfig struct {
+	Dir       string `json:"dir,omitempty"`       // default $XDG_CACHE_HOME/logviewer-tui
+	MaxSizeMB *int   `json:"maxSizeMB,omitempty"` // default 1024, 0 disables the cache
+}
+
+const defaultCacheSizeMB = 1024
+
+// open returns the cache, or nil when it is disabled.
+func (c cacheConfig) open() (*argo.Cache, error) {
+	size := defaultCacheSizeMB
+	if c.MaxSizeMB != nil {
+		size = *c.MaxSizeMB
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	dir := c.Dir
+	if dir == "" {
+		var err error
+		if dir, err = argo.DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+	return argo.NewCache(dir, int64(size)<<20), nil
+}
+
+// configPath returns $XDG_CONFIG_HOME/logviewer-tui/config.json (or the
+// platform equivalent).
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logviewer-tui", "config.json"), nil
+}
+
+// loadConfig reads and validates the config file at path. A missing file is
+// not an error unless the path was given explicitly.
+func loadConfig(path string, explicit bool) (config, error) {
+	var cfg config
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if errs := cfg.validate(); len(errs) > 0 {
+		return cfg, fmt.Errorf("%s:\n%w", path, errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+func (cfg config) validate() []error {
+	var errs []error
+	switch strings.ToUpper(cfg.Filters.Level) {
+	case "", "ERROR", "WARN", "INFO", "DEBUG":
+	default:
+		errs = append(errs, fmt.Errorf("filters.level: unknown level %q", cfg.Filters.Level))
+	}
+	for _, pattern := range cfg.Filters.Exclude {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("filters.exclude: %w", err))
+		}
+	}
+	for i, col := range cfg.Columns {
+		if strings.TrimSpace(col.Field) == "" {
+			errs = append(errs, fmt.Errorf("columns[%d]: field is empty", i))
+		}
+		if col.Width < 0 {
+			errs = append(errs, fmt.Errorf("columns[%d]: width must not be negative", i))
+		}
+	}
+	for level, color := range cfg.Colors {
+		if color == "" {
+			errs = append(errs, fmt.Errorf("colors.%s: color is empty", level))
+		}
+	}
+	errs = append(errs, validateThemes(cfg)...)
+	keys := defaultKeyMap()
+	errs = append(errs, keys.apply(cfg.Keys)...)
+	if err := cfg.Argo.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("argo: %w", err))
+	}
+	if cfg.Cache.MaxSizeMB != nil && *cfg.Cache.MaxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("cache.maxSizeMB: must not be negative"))
+	}
+	return errs
+}
+
+func saveConfig(path string, cfg config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"logviewer-tui/argo"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fetchProgress follows the log downloads of one fetch: the bytes received
+// per source for the progress bar, and the lines that arrived so far, which
+// the viewer shows before the downloads complete.
+type fetchProgress struct {
+	sources []stepSource
+	started time.Time
+	notify  chan struct{} // signalled on every chunk, closed once the fetch ends
+
+	mu        sync.Mutex
+	downloads map[string]*download // keyed by stepSource.key
+
+	// Owned by the model.
+	entries map[string][]logEntry // parsed so far, keyed by stepSource.key
+	shown   bool                  // the entries are in the viewer
+}
+
+type download struct {
+	pending     []byte // received bytes not handed to the viewer yet
+	restart     bool   // the body started over, after a retry or on another source
+	read, total int64
+}
+
+// received is the complete lines of one source since the last take.
+type received struct {
+	lines   string
+	restart bool
+}
+
+type fetchProgressMsg struct{ p *fetchProgress }
+
+func newFetchProgress(sources []stepSource) *fetchProgress {
+	return &fetchProgress{
+		sources:   sources,
+		started:   time.Now(),
+		notify:    make(chan struct{}, 1),
+		downloads: map[string]*download{},
+		entries:   map[string][]logEntry{},
+	}
+}
+
+// track returns the argo.Progress of one source.
+func (p *fetchProgress) track(src stepSource) argo.Progress {
+	return func(chunk []byte, read, total int64) {
+		p.mu.Lock()
+		d := p.downloads[src.key()]
+		if d == nil {
+			d = &download{}
+			p.downloads[src.key()] = d
+		}
+		if read == int64(len(chunk)) {
+			d.pending = d.pending[:0]
+			d.restart = true
+		}
+		d.pending = append(d.pending, chunk...)
+		d.read, d.total = read, total
+		p.mu.Unlock()
+
+		select {
+		case p.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// take hands out the complete lines received since the last call.
+func (p *fetchProgress) take() map[string]received {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := map[string]received{}
+	for k, d := range p.downloads {
+		end := bytes.LastIndexByte(d.pending, '\n') + 1
+		if end == 0 && !d.restart {
+			continue
+		}
+		out[k] = received{lines: string(d.pending[:end]), restart: d.restart}
+		d.pending = append(d.pending[:0], d.pending[end:]...)
+		d.restart = false
+	}
+	return out
+}
+
+// totals sums the bytes received; total is -1 until every source reported
+// its size.
+func (p *fetchProgress) totals() (read, total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, src := range p.sources {
+		d := p.downloads[src.key()]
+		if d == nil || d.total < 0 || total < 0 {
+			total = -1
+		} else {
+			total += d.total
+		}
+		if d != nil {
+			read += d.read
+		}
+	}
+	return read, total
+}
+
+// wait blocks until more bytes arrive, or a second passes so the elapsed
+// time stays current.
+func (p *fetchProgress) wait() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case _, ok := <-p.notify:
+			if !ok {
+				return nil
+			}
+		case <-time.After(time.Second):
+		}
+		return fetchProgressMsg{p}
+	}
+}
+
+// updateProgress parses the lines received since the last message and, once
+// there are entries, shows them in the viewer while the rest downloads.
+func (m model) updateProgress(msg fetchProgressMsg) (tea.Model, tea.Cmd) {
+	p := msg.p
+	if p != m.session.progress {
+		return m, nil
+	}
+	changed := false
+	for k, r := range p.take() {
+		var src stepSource
+		for _, s := range p.sources {
+			if s.key() == k {
+				src = s
+			}
+		}
+		if r.restart {
+			p.entries[k] = nil
+			changed = true
+		}
+		if parsed := m.parseSource(src, r.lines); len(parsed) > 0 {
+			p.entries[k] = append(p.entries[k], parsed...)
+			changed = true
+		}
+	}
+	if changed {
+		logs := m.partialLogs(p)
+		switch {
+		case p.shown:
+			m.logs = logs
+			m.cursor = min(m.cursor, max(0, len(m.pagedLogs())-1))
+		case len(logs) > 0 && m.mode == modeSteps:
+			m.showSources(p.sources, stepView{logs: logs})
+			p.shown = true
+		}
+	}
+	return m, p.wait()
+}
+
+// partialLogs merges what each source of a fetch has so far: the entries
+// received, or those already parsed for sources not being downloaded.
+func (m model) partialLogs(p *fetchProgress) []logEntry {
+	var steps [][]logEntry
+	for _, src := range p.sources {
+		if logs, ok := m.session.parsed[src.key()]; ok {
+			steps = append(steps, logs)
+		} else {
+			steps = append(steps, p.entries[src.key()])
+		}
+	}
+	return mergeSteps(steps)
+}
+
+// progressLine renders the download's bar, bytes and elapsed time.
+func (m model) progressLine(p *fetchProgress) string {
+	read, total := p.totals()
+	elapsed := time.Since(p.started).Truncate(time.Second)
+	line := "⬇ " + formatBytes(read)
+	if total > 0 {
+		line = "⬇ " + m.theme.bar(float64(read)/float64(total), 30) + " " + formatBytes(read) + " / " + formatBytes(total)
+	}
+	return line + " · " + elapsed.String() + " " + m.theme.Hint.Render("("+m.keys.View.Cancel.Help().Key+" to cancel)")
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// envelope is a log line as a container runtime wrote it to disk: Docker's
+// json-file driver, {"log":"...","stream":"stderr","time":"..."}, or the CRI
+// format of containerd and CRI-O, "<time> stdout F <payload>".
+type envelope struct {
+	format  string // "docker" or "cri"
+	stream  string // stdout or stderr
+	time    string
+	payload string
+	partial bool // continued by the stream's next line
+}
+
+var criLine = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\S+) (stdout|stderr) ([FP]) ?(.*)$`)
+
+// unwrapEnvelope recognizes a runtime envelope and returns its payload.
+func unwrapEnvelope(line string) (envelope, bool) {
+	if m := criLine.FindStringSubmatch(line); m != nil {
+		if _, err := time.Parse(time.RFC3339Nano, m[1]); err == nil {
+			return envelope{format: "cri", stream: m[2], time: m[1], payload: m[4], partial: m[3] == "P"}, true
+		}
+	}
+	if !strings.HasPrefix(line, "{") || !strings.Contains(line, `"log"`) || !strings.Contains(line, `"time"`) {
+		return envelope{}, false
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return envelope{}, false
+	}
+	var env envelope
+	for k, v := range raw {
+		var err error
+		switch k {
+		case "log":
+			err = json.Unmarshal(v, &env.payload)
+		case "stream":
+			err = json.Unmarshal(v, &env.stream)
+		case "time":
+			err = json.Unmarshal(v, &env.time)
+		case "attrs":
+		default:
+			// One of our own JSON lines that happens to have these keys.
+			return envelope{}, false
+		}
+		if err != nil {
+			return envelope{}, false
+		}
+	}
+	// Docker splits lines over 16KB; every part but the last lacks the
+	// newline.
+	env.format = "docker"
+	env.partial = !strings.HasSuffix(env.payload, "\n")
+	env.payload = strings.TrimRight(env.payload, "\r\n")
+	return env, true
+}
+
+// envelopes reassembles partial lines per stream, keeping the time of the
+// first part.
+type envelopes map[string]*envelope
+
+// add returns the complete envelope once its last part arrives.
+func (p envelopes) add(env envelope) (envelope, bool) {
+	if first := p[env.stream]; first != nil {
+		first.payload += env.payload
+		first.partial = env.partial
+		env = *first
+		delete(p, env.stream)
+	}
+	if env.partial {
+		p[env.stream] = &env
+		return envelope{}, false
+	}
+	return env, true
+}
+
+// rest returns the parts still waiting for their end, e.g. when the log was
+// cut off mid-line.
+func (p envelopes) rest() []envelope {
+	var rest []envelope
+	for _, stream := range []string{"stdout", "stderr"} {
+		if env := p[stream]; env != nil {
+			rest = append(rest, *env)
+		}
+	}
+	return rest
+}
+
+// metadata is what the runtime recorded about a line, kept as the entry's
+// "runtime" field.
+func (env envelope) metadata() map[string]interface{} {
+	return map[string]interface{}{"format": env.format, "stream": env.stream, "time": env.time}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnwrapEnvelope(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want envelope
+		ok   bool
+	}{
+		{
+			name: "docker",
+			line: `{"log":"{\"message\":\"hi\"}\n","stream":"stderr","time":"2025-03-13T16:05:36.1Z"}`,
+			want: envelope{format: "docker", stream: "stderr", time: "2025-03-13T16:05:36.1Z", payload: `{"message":"hi"}`},
+			ok:   true,
+		},
+		{
+			name: "docker partial",
+			line: `{"log":"{\"message\":","stream":"stdout","time":"2025-03-13T16:05:36Z","attrs":{"tag":"api"}}`,
+			want: envelope{format: "docker", stream: "stdout", time: "2025-03-13T16:05:36Z", payload: `{"message":`, partial: true},
+			ok:   true,
+		},
+		{
+			name: "cri",
+			line: `2025-03-13T16:05:36.123456789Z stdout F {"message":"hi"}`,
+			want: envelope{format: "cri", stream: "stdout", time: "2025-03-13T16:05:36.123456789Z", payload: `{"message":"hi"}`},
+			ok:   true,
+		},
+		{
+			name: "cri partial keeps trailing space",
+			line: `2025-03-13T16:05:36Z stderr P {"message":"split `,
+			want: envelope{format: "cri", stream: "stderr", time: "2025-03-13T16:05:36Z", payload: `{"message":"split `, partial: true},
+			ok:   true,
+		},
+		{name: "app json with extra keys", line: `{"log":"x","time":"y","level":"INFO","message":"plain"}`},
+		{name: "app json", line: `{"level":"INFO","timestamp":"2025-03-13T16:05:36Z","message":"hi"}`},
+		{name: "cri with bad time", line: `2025-13-45T99:99:99Z stdout F hi`},
+		{name: "cri with unknown stream", line: `2025-03-13T16:05:36Z stdin F hi`},
+		{name: "docker log not a string", line: `{"log":1,"stream":"stdout","time":"2025-03-13T16:05:36Z"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := unwrapEnvelope(tt.line)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("unwrapEnvelope() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseLogsEnvelopes(t *testing.T) {
+	type entry struct{ level, timestamp, message, stream string }
+	tests := []struct {
+		name  string
+		input []string
+		want  []entry
+	}{
+		{
+			name: "docker",
+			input: []string{
+				`{"log":"{\"level\":\"INFO\",\"timestamp\":\"t1\",\"message\":\"hello\"}\n","stream":"stdout","time":"2025-03-13T16:05:36Z"}`,
+			},
+			want: []entry{{"INFO", "t1", "hello", "stdout"}},
+		},
+		{
+			name: "docker partial lines",
+			input: []string{
+				`{"log":"{\"level\":\"WARN\",\"message\":\"par","stream":"stderr","time":"2025-03-13T16:05:37Z"}`,
+				`{"log":"{\"level\":\"INFO\",\"message\":\"between\"}\n","stream":"stdout","time":"2025-03-13T16:05:38Z"}`,
+				`{"log":"t two\"}\n","stream":"stderr","time":"2025-03-13T16:05:39Z"}`,
+			},
+			want: []entry{
+				{"INFO", "2025-03-13T16:05:38Z", "between", "stdout"},
+				{"WARN", "2025-03-13T16:05:37Z", "part two", "stderr"},
+			},
+		},
+		{
+			name: "cri partial lines per stream",
+			input: []string{
+				`2025-03-13T16:05:39Z stderr P {"level":"DEBUG","message":"split `,
+				`2025-03-13T16:05:39Z stdout F {"level":"INFO","timestamp":"t2","message":"whole"}`,
+				`2025-03-13T16:05:40Z stderr P across `,
+				`2025-03-13T16:05:41Z stderr F three"}`,
+			},
+			want: []entry{
+				{"INFO", "t2", "whole", "stdout"},
+				{"DEBUG", "2025-03-13T16:05:39Z", "split across three", "stderr"},
+			},
+		},
+		{
+			name: "cut-off partial line is kept",
+			input: []string{
+				`2025-03-13T16:05:41Z stdout F {"level":"INFO","timestamp":"t3","message":"done"}`,
+				`2025-03-13T16:05:42Z stdout P {"level":"ERROR","message":"trunc`,
+			},
+			want: []entry{
+				{"INFO", "t3", "done", "stdout"},
+				{"", "2025-03-13T16:05:42Z", `{"level":"ERROR","message":"trunc`, "stdout"},
+			},
+		},
+		{
+			name: "plain text payload",
+			input: []string{
+				`2025-03-13T16:05:43Z stderr F panic: boom`,
+			},
+			want: []entry{{"", "2025-03-13T16:05:43Z", "panic: boom", "stderr"}},
+		},
+		{
+			name: "null payload",
+			input: []string{
+				`2025-03-13T16:05:44Z stdout F null`,
+				`{"log":"null\n","stream":"stderr","time":"2025-03-13T16:05:45Z"}`,
+			},
+			want: []entry{
+				{"", "2025-03-13T16:05:44Z", "null", "stdout"},
+				{"", "2025-03-13T16:05:45Z", "null", "stderr"},
+			},
+		},
+		{
+			name: "app json and plain lines are unchanged",
+			input: []string{
+				`{"level":"INFO","timestamp":"t9","message":"plain","log":"x","time":"y"}`,
+				`not json`,
+			},
+			want: []entry{{"INFO", "t9", "plain", ""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []entry
+			for _, log := range parseLogs(strings.Join(tt.input, "\n"), defaultFieldMapping) {
+				stream := ""
+				if runtime, ok := log.Fields["runtime"].(map[string]interface{}); ok {
+					stream, _ = runtime["stream"].(string)
+				}
+				got = append(got, entry{log.Level, log.Timestamp, log.Message, stream})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLogs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEnvelopeMetadata(t *testing.T) {
+	logs := parseLogs(`2025-03-13T16:05:36.5Z stdout F {"level":"INFO","message":"hi","runtime":"app"}`+"\n"+
+		`2025-03-13T16:05:37Z stderr F {"level":"INFO","message":"hi"}`, defaultFieldMapping)
+	if len(logs) != 2 {
+		t.Fatalf("parseLogs() = %d entries", len(logs))
+	}
+	if got := logs[0].Details["runtime"]; got != "app" {
+		t.Errorf("payload's own runtime field = %v, want it kept", got)
+	}
+	want := map[string]interface{}{"format": "cri", "stream": "stderr", "time": "2025-03-13T16:05:37Z"}
+	if got := logs[1].Details["runtime"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("runtime = %v, want %v", got, want)
+	}
+	if got := logs[1].fieldValue("runtime.stream"); got != "stderr" {
+		t.Errorf(`fieldValue("runtime.stream") = %q`, got)
+	}
+
+	hidden := defaultFieldMapping
+	hidden.Hidden = append([]string{"runtime"}, hidden.Hidden...)
+	if logs := parseLogs(`2025-03-13T16:05:37Z stderr F {"message":"hi"}`, hidden); len(logs) != 1 || logs[0].Details["runtime"] != nil {
+		t.Errorf("hidden runtime field shown in details: %+v", logs)
+	}
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the key bindings of every mode. Each binding can be overridden
+// by name from the "keys" section of the config file.
+type keyMap struct {
+	Help       key.Binding
+	View       viewKeys
+	Paste      pasteKeys
+	Regex      regexKeys
+	Detail     detailKeys
+	Columns    columnKeys
+	Steps      stepKeys
+	Containers containerKeys
+	Summary    summaryKeys
+	Compare    compareKeys
+	Pods       podKeys
+	Browse     browseKeys
+	BrowseEdit browseEditKeys
+}
+
+type viewKeys struct {
+	Quit        key.Binding
+	Up          key.Binding
+	Down        key.Binding
+	Expand      key.Binding
+	Top         key.Binding
+	Bottom      key.Binding
+	Detail      key.Binding
+	FilterError key.Binding
+	FilterWarn  key.Binding
+	FilterInfo  key.Binding
+	FilterDebug key.Binding
+	FilterAll   key.Binding
+	Exclude     key.Binding
+	Columns     key.Binding
+	Split       key.Binding
+	Shrink      key.Binding
+	Grow        key.Binding
+	Follow      key.Binding
+	Steps       key.Binding
+	ToggleStep  key.Binding
+	Cancel      key.Binding
+	Back        key.Binding
+}
+
+type pasteKeys struct {
+	Done  key.Binding
+	Clear key.Binding
+	Quit  key.Binding
+}
+
+type regexKeys struct {
+	Apply  key.Binding
+	Cancel key.Binding
+}
+
+type detailKeys struct {
+	Up   key.Binding
+	Down key.Binding
+	Back key.Binding
+}
+
+type columnKeys struct {
+	Up       key.Binding
+	Down     key.Binding
+	MoveUp   key.Binding
+	MoveDown key.Binding
+	Narrower key.Binding
+	Wider    key.Binding
+	Toggle   key.Binding
+	Apply    key.Binding
+	Cancel   key.Binding
+}
+
+type stepKeys struct {
+	Mark       key.Binding
+	Open       key.Binding
+	Containers key.Binding
+	Summary    key.Binding
+	Compare    key.Binding
+	Browse     key.Binding
+	Cancel     key.Binding
+	Back       key.Binding
+	Quit       key.Binding
+}
+
+type containerKeys struct {
+	Up   key.Binding
+	Down key.Binding
+	Open key.Binding
+	Back key.Binding
+}
+
+type summaryKeys struct {
+	Up   key.Binding
+	Down key.Binding
+	Open key.Binding
+	Back key.Binding
+}
+
+type podKeys struct {
+	Up        key.Binding
+	Down      key.Binding
+	Mark      key.Binding
+	Open      key.Binding
+	Follow    key.Binding
+	More      key.Binding
+	Namespace key.Binding
+	Refresh   key.Binding
+	Cancel    key.Binding
+	Back      key.Binding
+	Quit      key.Binding
+}
+
+type browseKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Open   key.Binding
+	Filter key.Binding
+	Search key.Binding
+	Reload key.Binding
+	Cancel key.Binding
+	Back   key.Binding
+	Quit   key.Binding
+}
+
+// browseEditKeys edit the browser's filter or name search.
+type browseEditKeys struct {
+	Apply  key.Binding
+	Cancel key.Binding
+}
+
+type compareKeys struct {
+	Up       key.Binding
+	Down     key.Binding
+	DiffOnly key.Binding
+	Back     key.Binding
+}
+
+func bind(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys), desc))
+}
+
+// keyLabel is the help text for a list of keys, e.g. "q/ctrl+c".
+func keyLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case " ":
+			labels[i] = "space"
+		case "up":
+			labels[i] = "↑"
+		case "down":
+			labels[i] = "↓"
+		case "left":
+			labels[i] = "←"
+		case "right":
+			labels[i] = "→"
+		case "enter":
+			labels[i] = "⏎"
+		default:
+			labels[i] = k
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Help: bind("help", "?"),
+		View: viewKeys{
+			Quit:        bind("quit", "q", "ctrl+c"),
+			Up:          bind("up", "up"),
+			Down:        bind("down", "down"),
+			Expand:      bind("expand", "enter", " "),
+			Top:         bind("top", "home", "g"),
+			Bottom:      bind("bottom", "end", "G"),
+			Detail:      bind("view full JSON", "v"),
+			FilterError: bind("only errors", "e"),
+			FilterWarn:  bind("only warnings", "w"),
+			FilterInfo:  bind("only info", "i"),
+			FilterDebug: bind("only debug", "d"),
+			FilterAll:   bind("reset filters", "a"),
+			Exclude:     bind("regex exclude", "r"),
+			Columns:     bind("columns", "c"),
+			Split:       bind("split layout", "s"),
+			Shrink:      bind("shrink list", "<"),
+			Grow:        bind("grow list", ">"),
+			Follow:      bind("follow live logs", "f"),
+			Steps:       bind("workflow steps", "t"),
+			ToggleStep:  bind("show/hide merged step", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			Cancel:      bind("cancel download, stop live logs", "esc"),
+			Back:        bind("back to paste", "z"),
+		},
+		Paste: pasteKeys{
+			Done:  bind("done", "enter"),
+			Clear: bind("clear", "ctrl+z"),
+			Quit:  bind("quit", "esc", "ctrl+c"),
+		},
+		Regex: regexKeys{
+			Apply:  bind("apply filter", "enter"),
+			Cancel: bind("cancel", "esc", "ctrl+c"),
+		},
+		Detail: detailKeys{
+			Up:   bind("scroll up", "up"),
+			Down: bind("scroll down", "down"),
+			Back: bind("back", "q", "esc"),
+		},
+		Columns: columnKeys{
+			Up:       bind("up", "up", "k"),
+			Down:     bind("down", "down", "j"),
+			MoveUp:   bind("move up", "shift+up", "K"),
+			MoveDown: bind("move down", "shift+down", "J"),
+			Narrower: bind("narrower", "left", "-"),
+			Wider:    bind("wider", "right", "+"),
+			Toggle:   bind("toggle", " ", "x"),
+			Apply:    bind("apply & save", "enter"),
+			Cancel:   bind("cancel", "esc", "q"),
+		},
+		Steps: stepKeys{
+			Mark:       bind("mark for merged view", " "),
+			Open:       bind("open step(s)", "enter"),
+			Containers: bind("choose container", "c"),
+			Summary:    bind("workflow summary", "i"),
+			Compare:    bind("compare with --compare run", "="),
+			Browse:     bind("browse workflows", "b"),
+			Cancel:     bind("cancel loading", "x"),
+			Back:       bind("back to logs", "esc"),
+			Quit:       bind("quit", "q", "ctrl+c"),
+		},
+		Containers: containerKeys{
+			Up:   bind("up", "up", "k"),
+			Down: bind("down", "down", "j"),
+			Open: bind("open", "enter"),
+			Back: bind("back", "esc", "q"),
+		},
+		Summary: summaryKeys{
+			Up:   bind("up", "up", "k"),
+			Down: bind("down", "down", "j"),
+			Open: bind("open step logs", "enter"),
+			Back: bind("back", "esc", "q"),
+		},
+		Compare: compareKeys{
+			Up:       bind("up", "up", "k"),
+			Down:     bind("down", "down", "j"),
+			DiffOnly: bind("only differences", "d"),
+			Back:     bind("back", "esc", "q"),
+		},
+		Pods: podKeys{
+			Up:        bind("up", "up", "k"),
+			Down:      bind("down", "down", "j"),
+			Mark:      bind("mark for merged view", " "),
+			Open:      bind("fetch logs", "enter"),
+			Follow:    bind("follow logs", "f"),
+			More:      bind("more history", "+"),
+			Namespace: bind("namespace", "n"),
+			Refresh:   bind("refresh", "r"),
+			Cancel:    bind("cancel loading", "x"),
+			Back:      bind("back to logs", "esc"),
+			Quit:      bind("quit", "q", "ctrl+c"),
+		},
+		Browse: browseKeys{
+			Up:     bind("up", "up", "k"),
+			Down:   bind("down", "down", "j"),
+			Open:   bind("open workflow", "enter"),
+			Filter: bind("edit filter", "f"),
+			Search: bind("search names", "/"),
+			Reload: bind("reload", "ctrl+r"),
+			Cancel: bind("cancel loading", "x"),
+			Back:   bind("back to steps", "esc"),
+			Quit:   bind("quit", "q", "ctrl+c"),
+		},
+		BrowseEdit: browseEditKeys{
+			Apply:  bind("apply", "enter"),
+			Cancel: bind("cancel", "esc"),
+		},
+	}
+}
+
+type namedBinding struct {
+	name     string
+	binding  *key.Binding
+	fullOnly bool // left out of the footer, listed by the "?" overlay
+}
+
+// keyGroup is the set of bindings active in one mode. Config names are
+// prefixed with the group prefix, except for the viewer's own bindings.
+type keyGroup struct {
+	prefix   string
+	title    string
+	textual  bool // typing goes to a text input, so "?" is not available
+	bindings []namedBinding
+}
+
+func (k *keyMap) groups() []keyGroup {
+	return []keyGroup{
+		{prefix: "", title: "Log Viewer", bindings: []namedBinding{
+			{"help", &k.Help, false}, {"up", &k.View.Up, true}, {"down", &k.View.Down, true},
+			{"top", &k.View.Top, true}, {"bottom", &k.View.Bottom, true},
+			{"expand", &k.View.Expand, false}, {"detail", &k.View.Detail, false},
+			{"filter_error", &k.View.FilterError, true}, {"filter_warn", &k.View.FilterWarn, true},
+			{"filter_info", &k.View.FilterInfo, true}, {"filter_debug", &k.View.FilterDebug, true},
+			{"filter_all", &k.View.FilterAll, true}, {"exclude", &k.View.Exclude, false},
+			{"columns", &k.View.Columns, false}, {"split", &k.View.Split, false},
+			{"shrink", &k.View.Shrink, true}, {"grow", &k.View.Grow, true},
+			{"follow", &k.View.Follow, true}, {"steps", &k.View.Steps, false}, {"toggle_step", &k.View.ToggleStep, true},
+			{"cancel", &k.View.Cancel, true}, {"back", &k.View.Back, false},
+			{"quit", &k.View.Quit, false},
+		}},
+		{prefix: "paste.", title: "Paste Mode", textual: true, bindings: []namedBinding{
+			{"done", &k.Paste.Done, false}, {"clear", &k.Paste.Clear, false}, {"quit", &k.Paste.Quit, false},
+		}},
+		{prefix: "regex.", title: "Exclude Logs by Regex", textual: true, bindings: []namedBinding{
+			{"apply", &k.Regex.Apply, false}, {"cancel", &k.Regex.Cancel, false},
+		}},
+		{prefix: "detail.", title: "Full JSON Detail View", bindings: []namedBinding{
+			{"up", &k.Detail.Up, false}, {"down", &k.Detail.Down, false},
+			{"back", &k.Detail.Back, false}, {"help", &k.Help, false},
+		}},
+		{prefix: "columns.", title: "Columns", bindings: []namedBinding{
+			{"up", &k.Columns.Up, true}, {"down", &k.Columns.Down, true},
+			{"move_up", &k.Columns.MoveUp, false}, {"move_down", &k.Columns.MoveDown, false},
+			{"narrower", &k.Columns.Narrower, false}, {"wider", &k.Columns.Wider, false},
+			{"toggle", &k.Columns.Toggle, false}, {"apply", &k.Columns.Apply, false},
+			{"cancel", &k.Columns.Cancel, false}, {"help", &k.Help, false},
+		}},
+		{prefix: "steps.", title: "Workflow Steps", bindings: []namedBinding{
+			{"mark", &k.Steps.Mark, false}, {"open", &k.Steps.Open, false},
+			{"containers", &k.Steps.Containers, false}, {"summary", &k.Steps.Summary, false},
+			{"compare", &k.Steps.Compare, false}, {"browse", &k.Steps.Browse, false},
+			{"cancel", &k.Steps.Cancel, false},
+			{"back", &k.Steps.Back, false},
+			{"quit", &k.Steps.Quit, false}, {"help", &k.Help, false},
+		}},
+		{prefix: "containers.", title: "Containers", bindings: []namedBinding{
+			{"up", &k.Containers.Up, true}, {"down", &k.Containers.Down, true},
+			{"open", &k.Containers.Open, false}, {"back", &k.Containers.Back, false},
+			{"help", &k.Help, false},
+		}},
+		{prefix: "summary.", title: "Workflow Summary", bindings: []namedBinding{
+			{"up", &k.Summary.Up, true}, {"down", &k.Summary.Down, true},
+			{"open", &k.Summary.Open, false}, {"back", &k.Summary.Back, false},
+			{"help", &k.Help, false},
+		}},
+		{prefix: "compare.", title: "Compare Runs", bindings: []namedBinding{
+			{"up", &k.Compare.Up, true}, {"down", &k.Compare.Down, true},
+			{"diff_only", &k.Compare.DiffOnly, false}, {"back", &k.Compare.Back, false},
+			{"help", &k.Help, false},
+		}},
+		{prefix: "pods.", title: "Kubernetes Pods", bindings: []namedBinding{
+			{"up", &k.Pods.Up, true}, {"down", &k.Pods.Down, true},
+			{"mark", &k.Pods.Mark, false}, {"open", &k.Pods.Open, false},
+			{"follow", &k.Pods.Follow, false}, {"more", &k.Pods.More, false},
+			{"namespace", &k.Pods.Namespace, false},
+			{"refresh", &k.Pods.Refresh, true}, {"cancel", &k.Pods.Cancel, true},
+			{"back", &k.Pods.Back, false}, {"quit", &k.Pods.Quit, false},
+			{"help", &k.Help, false},
+		}},
+		{prefix: "browse.", title: "Browse Workflows", bindings: []namedBinding{
+			{"up", &k.Browse.Up, true}, {"down", &k.Browse.Down, true},
+			{"open", &k.Browse.Open, false}, {"filter", &k.Browse.Filter, false},
+			{"search", &k.Browse.Search, false}, {"reload", &k.Browse.Reload, true},
+			{"cancel", &k.Browse.Cancel, true}, {"back", &k.Browse.Back, false},
+			{"quit", &k.Browse.Quit, false}, {"help", &k.Help, false},
+		}},
+		{prefix: "browse_edit.", title: "Browse Workflows", textual: true, bindings: []namedBinding{
+			{"apply", &k.BrowseEdit.Apply, false}, {"cancel", &k.BrowseEdit.Cancel, false},
+		}},
+	}
+}
+
+// group returns the bindings active in mode.
+func (k *keyMap) group(mode int) keyGroup {
+	groups := k.groups()
+	switch mode {
+	case modePaste:
+		return groups[1]
+	case modeRegexFilter:
+		return groups[2]
+	case modeFullDetail:
+		return groups[3]
+	case modeColumns:
+		return groups[4]
+	case modeSteps:
+		return groups[5]
+	case modeContainers:
+		return groups[6]
+	case modeSummary:
+		return groups[7]
+	case modeCompare:
+		return groups[8]
+	case modePods:
+		return groups[9]
+	case modeBrowse:
+		return groups[10]
+	case modeBrowseEdit:
+		return groups[11]
+	default:
+		return groups[0]
+	}
+}
+
+// apply overrides bindings by config name and reports unknown actions, empty
+// key lists and keys bound to more than one action of the same mode.
+func (k *keyMap) apply(overrides map[string][]string) []error {
+	var errs []error
+	groups := k.groups()
+	byName := map[string]*key.Binding{}
+	for _, g := range groups {
+		for _, nb := range g.bindings {
+			name := g.prefix + nb.name
+			if nb.binding == &k.Help {
+				name = "help"
+			}
+			byName[name] = nb.binding
+		}
+	}
+
+	for action, keys := range overrides {
+		b, ok := byName[action]
+		if !ok {
+			errs = append(errs, fmt.Errorf("keys: unknown action %q", action))
+			continue
+		}
+		if len(keys) == 0 {
+			errs = append(errs, fmt.Errorf("keys.%s: no keys given", action))
+			continue
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(keyLabel(keys), b.Help().Desc)
+	}
+
+	for _, g := range groups {
+		owner := map[string]string{}
+		for _, nb := range g.bindings {
+			for _, k := range nb.binding.Keys() {
+				if other, ok := owner[k]; ok {
+					errs = append(errs, fmt.Errorf("keys: %q is bound to both %s%s and %s%s", k, g.prefix, other, g.prefix, nb.name))
+					continue
+				}
+				owner[k] = nb.name
+			}
+		}
+	}
+	return errs
+}
+
+// ShortHelp and FullHelp implement help.KeyMap for one mode.
+func (g keyGroup) ShortHelp() []key.Binding {
+	var bindings []key.Binding
+	for _, nb := range g.bindings {
+		if !nb.fullOnly {
+			bindings = append(bindings, *nb.binding)
+		}
+	}
+	return bindings
+}
+
+func (g keyGroup) FullHelp() [][]key.Binding {
+	const perColumn = 8
+	var columns [][]key.Binding
+	var bindings []key.Binding
+	for _, nb := range g.bindings {
+		bindings = append(bindings, *nb.binding)
+	}
+	for len(bindings) > perColumn {
+		columns = append(columns, bindings[:perColumn])
+		bindings = bindings[perColumn:]
+	}
+	return append(columns, bindings)
+}
@@ -0,0 +1,166 @@
+package kube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client reads pods and container logs from the Kubernetes API.
+type Client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+// NewClient returns a client for cfg.
+func NewClient(cfg Config) (*Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if len(cfg.CAData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(cfg.CAData) {
+			return nil, fmt.Errorf("kubeconfig: invalid certificate-authority-data")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(cfg.CertData) > 0 {
+		cert, err := tls.X509KeyPair(cfg.CertData, cfg.KeyData)
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig: client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &Client{
+		server: strings.TrimRight(cfg.Server, "/"),
+		token:  cfg.Token,
+		http:   &http.Client{Transport: transport},
+	}, nil
+}
+
+// PodLogs returns the logs of one container of a pod.
+func (k *Client) PodLogs(ctx context.Context, namespace, pod, container string) (string, error) {
+	return k.DownloadPodLogs(ctx, namespace, pod, container, nil)
+}
+
+// DownloadPodLogs is PodLogs reporting the body to progress as it arrives.
+func (k *Client) DownloadPodLogs(ctx context.Context, namespace, pod, container string, progress Progress) (string, error) {
+	return k.download(ctx, progress, fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/log?%s", k.server,
+		url.PathEscape(namespace), url.PathEscape(pod), url.Values{"container": {container}}.Encode()))
+}
+
+// PodContainers returns the names of a pod's init containers followed by
+// its containers.
+func (k *Client) PodContainers(ctx context.Context, namespace, pod string) ([]string, error) {
+	u := fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s", k.server, url.PathEscape(namespace), url.PathEscape(pod))
+	body, err := k.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	var p struct {
+		Spec struct {
+			InitContainers []struct{ Name string } `json:"initContainers"`
+			Containers     []struct{ Name string } `json:"containers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", u, err)
+	}
+	var names []string
+	for _, c := range append(p.Spec.InitContainers, p.Spec.Containers...) {
+		names = append(names, c.Name)
+	}
+	return names, nil
+}
+
+func (k *Client) get(ctx context.Context, u string) (string, error) {
+	return k.download(ctx, nil, u)
+}
+
+func (k *Client) download(ctx context.Context, progress Progress, u string) (string, error) {
+	resp, err := k.open(ctx, u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp, progress)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", u, err)
+	}
+	return string(body), nil
+}
+
+// open performs an authenticated GET and returns a 200 response, whose body
+// the caller must close.
+func (k *Client) open(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if k.token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	}
+	resp, err := k.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, URL: u, Body: string(body)}
+	}
+	return resp, nil
+}
+
+// Progress receives a download's body chunk by chunk, with the bytes read so
+// far and the total size, or -1 when the server does not send one.
+type Progress func(chunk []byte, read, total int64)
+
+// readBody reads a response body, handing each chunk to progress if set.
+func readBody(resp *http.Response, progress Progress) ([]byte, error) {
+	if progress == nil {
+		return io.ReadAll(resp.Body)
+	}
+	var body []byte
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			body = append(body, buf[:n]...)
+			progress(buf[:n], int64(len(body)), resp.ContentLength)
+		}
+		if err == io.EOF {
+			return body, nil
+		}
+		if err != nil {
+			return body, err
+		}
+	}
+}
+
+// ErrNotFound matches APIErrors with a 404 status via errors.Is.
+var ErrNotFound = errors.New("not found")
+
+// APIError is a non-2xx response from the Kubernetes API.
+type APIError struct {
+	StatusCode int
+	URL        string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (%d): %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,49 @@
+package kube
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newKubeServer starts a TLS stand-in for the Kubernetes API that checks the
+// token and hands requests to h.
+func newKubeServer(t *testing.T, h http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer kube-token" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	kube, err := NewClient(Config{Server: srv.URL, CAData: ca, Token: "kube-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kube
+}
+
+func TestPodLogs(t *testing.T) {
+	kube := newKubeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/cas/pods/sync-gj97n-2/log" || r.URL.Query().Get("container") != "main" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("from pod\n"))
+	})
+
+	logs, err := kube.PodLogs(context.Background(), "cas", "sync-gj97n-2", "main")
+	if err != nil || logs != "from pod\n" {
+		t.Fatalf("PodLogs() = %q, %v", logs, err)
+	}
+	if _, err := kube.PodLogs(context.Background(), "cas", "gone", "main"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
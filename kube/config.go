@@ -0,0 +1,172 @@
+// Package kube reads pods and container logs straight from the Kubernetes
+// API, with the credentials of the current kubeconfig context.
+package kube
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// kubeconfig is the current context of `kubectl config view --minify
+// --flatten`, which inlines certificate files as data.
+type kubeconfig struct {
+	Clusters []struct {
+		Cluster struct {
+			Server                   string `json:"server"`
+			CertificateAuthorityData []byte `json:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+	Contexts []struct {
+		Context struct {
+			Namespace string `json:"namespace"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Users []struct {
+		User kubeconfigUser `json:"user"`
+	} `json:"users"`
+}
+
+type kubeconfigUser struct {
+	Token                 string `json:"token"`
+	TokenFile             string `json:"tokenFile"`
+	ClientCertificateData []byte `json:"client-certificate-data"`
+	ClientKeyData         []byte `json:"client-key-data"`
+	Exec                  *struct {
+		APIVersion string   `json:"apiVersion"`
+		Command    string   `json:"command"`
+		Args       []string `json:"args"`
+		Env        []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"env"`
+	} `json:"exec"`
+}
+
+func readKubeconfig() (kubeconfig, error) {
+	var kc kubeconfig
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return kc, fmt.Errorf("kubectl not found in PATH")
+	}
+	out, err := runCommand(exec.Command("kubectl", "config", "view", "--minify", "--flatten", "-o", "json"))
+	if err != nil {
+		return kc, err
+	}
+	if err := json.Unmarshal([]byte(out), &kc); err != nil {
+		return kc, fmt.Errorf("parse kubeconfig: %w", err)
+	}
+	return kc, nil
+}
+
+// token returns the user's bearer token from the kubeconfig, a token file
+// or an exec credential plugin.
+func (user kubeconfigUser) token() (string, error) {
+	switch {
+	case user.Token != "":
+		return user.Token, nil
+	case user.TokenFile != "":
+		data, err := os.ReadFile(user.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case user.Exec != nil:
+		cmd := exec.Command(user.Exec.Command, user.Exec.Args...)
+		cmd.Env = os.Environ()
+		for _, e := range user.Exec.Env {
+			cmd.Env = append(cmd.Env, e.Name+"="+e.Value)
+		}
+		execInfo := fmt.Sprintf(`{"apiVersion":%q,"kind":"ExecCredential","spec":{"interactive":false}}`, user.Exec.APIVersion)
+		cmd.Env = append(cmd.Env, "KUBERNETES_EXEC_INFO="+execInfo)
+		out, err := runCommand(cmd)
+		if err != nil {
+			return "", fmt.Errorf("exec plugin %s: %w", user.Exec.Command, err)
+		}
+		var cred struct {
+			Status struct {
+				Token string `json:"token"`
+			} `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(out), &cred); err != nil {
+			return "", fmt.Errorf("exec plugin %s: %w", user.Exec.Command, err)
+		}
+		if cred.Status.Token == "" {
+			return "", fmt.Errorf("exec plugin %s returned no token (client certificates are not supported)", user.Exec.Command)
+		}
+		return cred.Status.Token, nil
+	default:
+		return "", fmt.Errorf("user has no token, tokenFile or exec plugin")
+	}
+}
+
+// Config is what Client needs to reach the Kubernetes API server. Token may
+// be empty when the client certificate authenticates.
+type Config struct {
+	Server             string
+	CAData             []byte // PEM
+	InsecureSkipVerify bool
+	CertData, KeyData  []byte // PEM client certificate and key
+	Token              string
+	Namespace          string // of the current context, "" for default
+}
+
+// LoadConfig reads the current kubeconfig context through kubectl.
+func LoadConfig() (Config, error) {
+	kc, err := readKubeconfig()
+	if err != nil {
+		return Config{}, err
+	}
+	if len(kc.Clusters) == 0 || kc.Clusters[0].Cluster.Server == "" {
+		return Config{}, fmt.Errorf("current context has no cluster")
+	}
+	cluster := kc.Clusters[0].Cluster
+	cfg := Config{
+		Server:             cluster.Server,
+		CAData:             cluster.CertificateAuthorityData,
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+	}
+	if len(kc.Contexts) > 0 {
+		cfg.Namespace = kc.Contexts[0].Context.Namespace
+	}
+	if len(kc.Users) > 0 {
+		user := kc.Users[0].User
+		cfg.CertData, cfg.KeyData = user.ClientCertificateData, user.ClientKeyData
+		if token, err := user.token(); err == nil {
+			cfg.Token = strings.TrimSpace(token)
+		} else if len(cfg.CertData) == 0 {
+			return Config{}, fmt.Errorf("kubeconfig user: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// UserToken returns the bearer token, token file contents or exec credential
+// plugin token of the current kubeconfig user.
+func UserToken() (string, error) {
+	kc, err := readKubeconfig()
+	if err != nil {
+		return "", err
+	}
+	if len(kc.Users) == 0 {
+		return "", fmt.Errorf("current context has no user")
+	}
+	return kc.Users[0].User.token()
+}
+
+// runCommand returns stdout, or an error carrying the command's stderr.
+func runCommand(cmd *exec.Cmd) (string, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
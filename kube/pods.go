@@ -0,0 +1,156 @@
+package kube
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pod is the subset of a Kubernetes pod the pod picker shows.
+type Pod struct {
+	Namespace      string
+	Name           string
+	Phase          string
+	StartedAt      time.Time
+	Containers     []string
+	InitContainers []string
+}
+
+type metadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ListNamespaces returns the names of the cluster's namespaces, sorted.
+func (k *Client) ListNamespaces(ctx context.Context) ([]string, error) {
+	u := k.server + "/api/v1/namespaces"
+	body, err := k.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Items []struct {
+			Metadata metadata `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", u, err)
+	}
+	names := make([]string, len(list.Items))
+	for i, item := range list.Items {
+		names[i] = item.Metadata.Name
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ListPods returns the pods of a namespace, or of every namespace when it
+// is empty, that match a label selector such as "app=api,tier!=cache".
+// They are sorted by namespace, then name.
+func (k *Client) ListPods(ctx context.Context, namespace, selector string) ([]Pod, error) {
+	u := k.server + "/api/v1/pods"
+	if namespace != "" {
+		u = k.server + "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods"
+	}
+	if selector != "" {
+		u += "?" + url.Values{"labelSelector": {selector}}.Encode()
+	}
+	body, err := k.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Items []struct {
+			Metadata metadata `json:"metadata"`
+			Spec     struct {
+				InitContainers []struct{ Name string } `json:"initContainers"`
+				Containers     []struct{ Name string } `json:"containers"`
+			} `json:"spec"`
+			Status struct {
+				Phase     string    `json:"phase"`
+				StartTime time.Time `json:"startTime"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", u, err)
+	}
+	pods := make([]Pod, len(list.Items))
+	for i, item := range list.Items {
+		pod := Pod{
+			Namespace: item.Metadata.Namespace,
+			Name:      item.Metadata.Name,
+			Phase:     item.Status.Phase,
+			StartedAt: item.Status.StartTime,
+		}
+		for _, c := range item.Spec.InitContainers {
+			pod.InitContainers = append(pod.InitContainers, c.Name)
+		}
+		for _, c := range item.Spec.Containers {
+			pod.Containers = append(pod.Containers, c.Name)
+		}
+		pods[i] = pod
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+// TailPodLogs returns the last tailLines lines of a container's log, or all
+// of it when tailLines is 0.
+func (k *Client) TailPodLogs(ctx context.Context, namespace, pod, container string, tailLines int) (string, error) {
+	return k.download(ctx, nil, k.logURL(namespace, pod, container, tailLines, false))
+}
+
+// StreamPodLogs follows a container's log, starting with the last tailLines
+// lines it already wrote, or all of them when tailLines is 0. The stream
+// ends with io.EOF once the container exits; cancel ctx or call Close to
+// stop earlier.
+func (k *Client) StreamPodLogs(ctx context.Context, namespace, pod, container string, tailLines int) (*LogStream, error) {
+	resp, err := k.open(ctx, k.logURL(namespace, pod, container, tailLines, true))
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(resp.Body)
+	return &LogStream{body: resp.Body, next: func() (string, error) {
+		line, err := r.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}}, nil
+}
+
+func (k *Client) logURL(namespace, pod, container string, tailLines int, follow bool) string {
+	query := url.Values{"container": {container}}
+	if tailLines > 0 {
+		query.Set("tailLines", strconv.Itoa(tailLines))
+	}
+	if follow {
+		query.Set("follow", "true")
+	}
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/log?%s", k.server,
+		url.PathEscape(namespace), url.PathEscape(pod), query.Encode())
+}
+
+// LogStream reads the lines of a running container as the API sends them.
+type LogStream struct {
+	body io.ReadCloser
+	next func() (string, error)
+}
+
+// Next returns the next log line, or io.EOF at the end of the stream.
+func (s *LogStream) Next() (string, error) { return s.next() }
+
+func (s *LogStream) Close() error { return s.body.Close() }
@@ -0,0 +1,98 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testPods = `{"items": [
+  {"metadata": {"name": "api-7d9f-b", "namespace": "shop"},
+   "spec": {"containers": [{"name": "api"}, {"name": "istio-proxy"}]},
+   "status": {"phase": "Running", "startTime": "2025-03-13T16:05:36Z"}},
+  {"metadata": {"name": "api-7d9f-a", "namespace": "shop"},
+   "spec": {"initContainers": [{"name": "migrate"}], "containers": [{"name": "api"}]},
+   "status": {"phase": "Pending"}}
+]}`
+
+func TestListPods(t *testing.T) {
+	kube := newKubeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/namespaces":
+			fmt.Fprint(w, `{"items": [{"metadata": {"name": "shop"}}, {"metadata": {"name": "default"}}]}`)
+		case r.URL.Path == "/api/v1/namespaces/shop/pods" && r.URL.Query().Get("labelSelector") == "app=api":
+			fmt.Fprint(w, testPods)
+		case r.URL.Path == "/api/v1/pods" && r.URL.Query().Get("labelSelector") == "":
+			fmt.Fprint(w, `{"items": []}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	namespaces, err := kube.ListNamespaces(context.Background())
+	if err != nil || strings.Join(namespaces, ",") != "default,shop" {
+		t.Errorf("ListNamespaces() = %q, %v", namespaces, err)
+	}
+	pods, err := kube.ListPods(context.Background(), "shop", "app=api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 2 || pods[0].Name != "api-7d9f-a" || pods[0].InitContainers[0] != "migrate" ||
+		strings.Join(pods[1].Containers, ",") != "api,istio-proxy" || pods[1].StartedAt.IsZero() {
+		t.Errorf("ListPods() = %+v", pods)
+	}
+	if pods, err := kube.ListPods(context.Background(), "", ""); err != nil || len(pods) != 0 {
+		t.Errorf("ListPods(all namespaces) = %+v, %v", pods, err)
+	}
+}
+
+func TestStreamPodLogs(t *testing.T) {
+	kube := newKubeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v1/namespaces/shop/pods/api-7d9f-a/log" || q.Get("follow") != "true" || q.Get("tailLines") != "100" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "{\"message\":\"one\"}\r\n{\"message\":\"two\"}\nno newline")
+	})
+
+	stream, err := kube.StreamPodLogs(context.Background(), "shop", "api-7d9f-a", "api", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	var lines []string
+	for {
+		line, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	if want := `{"message":"one"}|{"message":"two"}|no newline`; strings.Join(lines, "|") != want {
+		t.Errorf("lines = %q, want %q", strings.Join(lines, "|"), want)
+	}
+}
+
+func TestTailPodLogs(t *testing.T) {
+	kube := newKubeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v1/namespaces/shop/pods/api-7d9f-a/log" || q.Get("container") != "api" || q.Has("follow") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "tail=%q", q.Get("tailLines"))
+	})
+
+	for tail, want := range map[int]string{1000: `tail="1000"`, 0: `tail=""`} {
+		if logs, err := kube.TailPodLogs(context.Background(), "shop", "api-7d9f-a", "api", tail); err != nil || logs != want {
+			t.Errorf("TailPodLogs(%d) = %q, %v, want %q", tail, logs, err, want)
+		}
+	}
+}
@@ -1,26 +1,174 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"os"
 
 	"logviewer-tui/argo"
+	"logviewer-tui/kube"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
 	workflow := flag.String("workflow", "", "Argo workflow name e.g 'sync-customer-template-gj97n'")
+	browse := flag.Bool("browse", false, "Browse live and archived Argo workflows to pick one")
+	compare := flag.String("compare", "", "Baseline workflow (name or UID) to compare steps of --workflow or --browse with")
+	browseFilter := flag.String("browse-filter", "", "Initial browser filter e.g 'sync- label:team=cas phase:Failed since:12h'")
+	cfgFlag := flag.String("config", "", "Path to the config file (default $XDG_CONFIG_HOME/logviewer-tui/config.json)")
+	argoServer := flag.String("argo-server", "", "Argo server URL or host:port (env ARGO_SERVER)")
+	argoNamespace := flag.String("argo-namespace", "", "Namespace of the workflows (env ARGO_NAMESPACE)")
+	argoArtifactRepo := flag.String("argo-artifact-repository", "", "Namespace segment of artifact-files URLs (default: the workflow namespace)")
+	argoBasePath := flag.String("argo-base-path", "", "Base path the Argo server is served under (env ARGO_BASE_HREF)")
+	argoSecure := flag.Bool("argo-secure", true, "Use https for a host:port server (env ARGO_SECURE)")
+	argoInsecure := flag.Bool("argo-insecure-skip-verify", false, "Skip TLS certificate verification (env ARGO_INSECURE_SKIP_VERIFY)")
+	argoTimeout := flag.Duration("argo-timeout", 0, "How long an Argo request attempt may wait for data before it is retried (default 30s)")
+	argoRetries := flag.Int("argo-retries", 0, "Retries after an Argo 5xx or connection error (default 3)")
+	token := flag.String("token", "", "Argo bearer token (env ARGO_TOKEN takes precedence)")
+	tokenFile := flag.String("token-file", "", "File holding an Argo bearer token")
+	portForward := flag.Bool("argo-port-forward", false, "Run kubectl port-forward to the argo-server service instead of using --argo-server")
+	serverNamespace := flag.String("argo-server-namespace", "", "Namespace of the argo-server service to port-forward to (default argo)")
+	offline := flag.Bool("offline", false, "Read workflows and logs only from the local cache")
+	pods := flag.String("pods", "", "Label selector of Kubernetes pods to read logs from e.g 'app=api' (empty for every pod)")
+	podsNamespace := flag.String("pods-namespace", "", "Namespace of the --pods pods (default: the kubeconfig context's)")
+	podsAllNamespaces := flag.Bool("pods-all-namespaces", false, "Look for --pods pods in every namespace")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n       %s cache list|clear [workflow]\n\nFlags:\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
-	m := initialModel()
-	if *workflow != "" {
-		logs, err := argo.RunWorkflowMode(*workflow)
-		if err != nil || logs == "" {
-			fmt.Println("❌ Failed to fetch logs:", err)
-			return
-		}
-		m.logs = parseLogs(logs)
-		m.mode = modeView
+
+	cfgPath := *cfgFlag
+	if cfgPath == "" {
+		path, err := configPath()
+		if err != nil {
+			fmt.Println("❌ Failed to locate config directory:", err)
+			os.Exit(1)
+		}
+		cfgPath = path
+	}
+	cfg, err := loadConfig(cfgPath, *cfgFlag != "")
+	if err != nil {
+		fmt.Println("❌ Invalid config:", err)
+		os.Exit(1)
+	}
+
+	// Argo settings: flags win over the environment, which wins over the
+	// config file. They are kept apart from cfg, which the column picker
+	// saves back to the file.
+	argoCfg, err := cfg.Argo.WithEnv(os.Getenv)
+	if err != nil {
+		fmt.Println("❌ Invalid environment:", err)
+		os.Exit(1)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "argo-server":
+			argoCfg.Server = *argoServer
+		case "argo-namespace":
+			argoCfg.Namespace = *argoNamespace
+		case "argo-artifact-repository":
+			argoCfg.ArtifactRepository = *argoArtifactRepo
+		case "argo-base-path":
+			argoCfg.BasePath = *argoBasePath
+		case "argo-secure":
+			argoCfg.Secure = argoSecure
+		case "argo-insecure-skip-verify":
+			argoCfg.InsecureSkipVerify = *argoInsecure
+		case "argo-timeout":
+			argoCfg.Timeout = argoTimeout.String()
+		case "argo-retries":
+			argoCfg.Retries = argoRetries
+		case "argo-port-forward":
+			argoCfg.PortForward = *portForward
+		case "argo-server-namespace":
+			argoCfg.ServerNamespace = *serverNamespace
+		case "token":
+			argoCfg.Token = *token
+		case "token-file":
+			argoCfg.TokenFile = *tokenFile
+		}
+	})
+	if err := argoCfg.Validate(); err != nil {
+		fmt.Println("❌ Invalid Argo settings:", err)
+		os.Exit(1)
+	}
+	cache, err := cfg.Cache.open()
+	if err != nil {
+		fmt.Println("❌ Failed to locate cache directory:", err)
+		os.Exit(1)
+	}
+	if flag.Arg(0) == "cache" {
+		os.Exit(cacheCommand(cache, flag.Args()[1:]))
+	}
+	podsSet := false
+	flag.Visit(func(f *flag.Flag) { podsSet = podsSet || f.Name == "pods" })
+	if podsSet && (*workflow != "" || *browse || *offline) {
+		fmt.Println("❌ --pods reads from the cluster and cannot be combined with --workflow, --browse or --offline")
+		os.Exit(1)
+	}
+	if *compare != "" && *workflow == "" && !*browse {
+		fmt.Println("❌ --compare needs --workflow or --browse to pick the run to compare")
+		os.Exit(1)
+	}
+	if *offline && cache == nil {
+		fmt.Println("❌ --offline needs the cache, which cache.maxSizeMB disables")
+		os.Exit(1)
+	}
+
+	m := initialModel(cfg, cfgPath)
+	var pf *argo.PortForward
+	if (*workflow != "" || *browse) && argoCfg.PortForward && !*offline {
+		fmt.Println("🔌 Starting kubectl port-forward to argo-server…")
+		pf, err = argo.StartPortForward(context.Background(), argoCfg)
+		if err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+		argoCfg.Server = pf.Server()
+	}
+	defer pf.Close()
+
+	if *workflow != "" || *browse {
+		var opts []argo.Option
+		if cache != nil {
+			opts = append(opts, argo.WithCache(cache))
+		}
+		if *offline {
+			opts = append(opts, argo.WithOffline())
+		}
+		client := argo.NewClient(argoCfg, opts...)
+		if *browse {
+			m.openBrowser(client, *browseFilter)
+		} else {
+			m.openWorkflow(client, *workflow, nil)
+		}
+		m.session.compareName = *compare
+	}
+
+	if podsSet {
+		kc, err := kube.LoadConfig()
+		if err != nil {
+			fmt.Println("❌ Failed to load kubeconfig:", err)
+			os.Exit(1)
+		}
+		kubeClient, err := kube.NewClient(kc)
+		if err != nil {
+			fmt.Println("❌", err)
+			os.Exit(1)
+		}
+		namespace := *podsNamespace
+		switch {
+		case *podsAllNamespaces:
+			namespace = ""
+		case namespace == "" && kc.Namespace != "":
+			namespace = kc.Namespace
+		case namespace == "":
+			namespace = "default"
+		}
+		m.openPods(kubeClient, namespace, *pods)
 	}
 
 	if _, err := tea.NewProgram(m).Run(); err != nil {
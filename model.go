@@ -1,16 +1,15 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"os"
 	"regexp"
-	"sort"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 const (
@@ -18,6 +17,14 @@ const (
 	modeView
 	modeRegexFilter
 	modeFullDetail
+	modeColumns
+	modeSteps
+	modeContainers
+	modeSummary
+	modeCompare
+	modePods
+	modeBrowse
+	modeBrowseEdit
 )
 
 type model struct {
@@ -33,13 +40,47 @@ type model struct {
 	excludePatterns []*regexp.Regexp
 	fullDetailLines []string
 	detailOffset    int
+	cfg             config
+	columns         []column
+	pickerItems     []pickerItem
+	pickerCursor    int
+	statusMessage   string
+	split           int
+	splitRatio      int
+	cfgPath         string
+	keys            keyMap
+	fields          fieldMapping
+	theme           theme
+	help            help.Model
+	showHelp        bool
+	session         workflowSession
+	pods            podSession
+	browse          browseSession
+	hiddenSteps     map[string]bool // merged Argo sources left out of the list
+	follow          bool            // keep the cursor on the newest streamed entry
 }
 
 func (m model) Init() tea.Cmd {
-	return textarea.Blink
+	var cmds []tea.Cmd
+	if m.mode == modeSteps && m.session.wf == nil {
+		cmds = append(cmds, m.loadWorkflow())
+	}
+	if m.session.compareName != "" && m.session.client != nil {
+		cmds = append(cmds, m.loadCompare())
+	}
+	if m.mode == modeBrowse {
+		cmds = append(cmds, m.loadWorkflows())
+	}
+	if m.mode == modePods {
+		cmds = append(cmds, m.loadPods())
+	}
+	if len(cmds) == 0 {
+		return textarea.Blink
+	}
+	return tea.Batch(cmds...)
 }
 
-func initialModel() model {
+func initialModel(cfg config, cfgPath string) model {
 	ta := textarea.New()
 	ta.Placeholder = "Paste logs here and press Enter when done..."
 	ta.Focus()
@@ -50,15 +91,39 @@ func initialModel() model {
 	regexTA.CharLimit = 0
 	regexTA.SetHeight(3)
 
+	columns := cfg.Columns
+	if len(columns) == 0 {
+		columns = defaultColumns
+	}
+	keys := defaultKeyMap()
+	keys.apply(cfg.Keys) // already validated by loadConfig
+
+	th := resolveTheme(cfg)
+
+	var exclude []*regexp.Regexp
+	for _, pattern := range cfg.Filters.Exclude {
+		exclude = append(exclude, regexp.MustCompile(pattern))
+	}
+
 	return model{
-		mode:       modePaste,
-		textarea:   ta,
-		regexInput: regexTA,
+		mode:            modePaste,
+		textarea:        ta,
+		regexInput:      regexTA,
+		cfg:             cfg,
+		cfgPath:         cfgPath,
+		columns:         columns,
+		splitRatio:      defaultSplitRatio,
+		keys:            keys,
+		fields:          cfg.Fields.withDefaults(),
+		theme:           th,
+		help:            newHelp(th),
+		filter:          strings.ToUpper(cfg.Filters.Level),
+		excludePatterns: exclude,
 	}
 }
 
 func (m model) pageSize() int {
-	size := m.height - 6
+	size := m.listHeight() - 2
 	if size < 1 {
 		return 1
 	}
@@ -81,9 +146,22 @@ func (m *model) scrollDown() {
 	}
 }
 
+func (m *model) scrollToBottom() {
+	logCount := len(m.filteredLogs())
+	pageSize := m.pageSize()
+
+	if logCount > pageSize {
+		m.offset = logCount - pageSize
+		m.cursor = pageSize - 1
+	} else {
+		m.offset = 0
+		m.cursor = max(0, logCount-1)
+	}
+}
+
 func (m model) findLogIndex(target logEntry) int {
 	for i, log := range m.logs {
-		if log.Timestamp == target.Timestamp && log.Message == target.Message && log.Level == target.Level {
+		if log.Timestamp == target.Timestamp && log.Message == target.Message && log.Level == target.Level && log.Source == target.Source {
 			return i
 		}
 	}
@@ -97,6 +175,9 @@ outer:
 		if m.filter != "" && !strings.EqualFold(log.Level, m.filter) {
 			continue
 		}
+		if m.hiddenSteps[log.Source] {
+			continue
+		}
 		combined := log.Message + " " + log.Level + " " + log.Timestamp
 		for _, re := range m.excludePatterns {
 			if re.MatchString(combined) {
@@ -112,7 +193,7 @@ func (m model) pagedLogs() []logEntry {
 	var page []logEntry
 	logs := m.filteredLogs()
 
-	linesAvailable := m.height - 4 // room for header + footer
+	linesAvailable := m.listHeight()
 	linesUsed := 0
 
 	for i := m.offset; i < len(logs); i++ {
@@ -120,7 +201,7 @@ func (m model) pagedLogs() []logEntry {
 
 		used := 1 // base line
 		if log.Expanded && len(log.Details) > 0 {
-			used += strings.Count(renderStyledJSON(log.Details), "\n")
+			used += strings.Count(m.theme.renderJSON(log.Details), "\n")
 		}
 
 		if linesUsed+used > linesAvailable {
@@ -148,59 +229,43 @@ func compileRegexList(input string) []*regexp.Regexp {
 	return patterns
 }
 
-func renderStyledJSONLines(data map[string]interface{}, width int) []string {
-	var lines []string
-	cyan := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))    // keys
-	green := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))   // strings
-	yellow := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))  // numbers
-	magenta := lipgloss.NewStyle().Foreground(lipgloss.Color("5")) // booleans
-	gray := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))    // null
-
-	wrap := func(s string) []string {
-		var wrapped []string
-		for len(s) > width {
-			wrapped = append(wrapped, s[:width])
-			s = s[width:]
-		}
-		wrapped = append(wrapped, s)
-		return wrapped
-	}
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
 
-	renderValue := func(v interface{}) string {
-		switch val := v.(type) {
-		case string:
-			return green.Render(fmt.Sprintf(`"%s"`, val))
-		case float64, int:
-			return yellow.Render(fmt.Sprintf("%v", val))
-		case bool:
-			return magenta.Render(fmt.Sprintf("%v", val))
-		case nil:
-			return gray.Render("null")
-		default:
-			encoded, _ := json.Marshal(val)
-			return green.Render(string(encoded))
-		}
-	}
+// pendingFetch is the context of a request the user can cancel. Replies
+// carry the context they answer, so that one superseded by a newer request
+// is dropped.
+type pendingFetch struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
 
-	var keys []string
-	for k := range data {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+// start begins a request, ending the previous one.
+func (f *pendingFetch) start() {
+	f.end()
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+}
 
-	for _, k := range keys {
-		key := cyan.Render(fmt.Sprintf(`"%s"`, k))
-		val := renderValue(data[k])
-		line := fmt.Sprintf("  %s: %s", key, val)
-		lines = append(lines, wrap(line)...)
+// end cancels the pending request, if any; the cancel key does nothing until
+// the next one starts.
+func (f *pendingFetch) end() {
+	if f.cancel != nil {
+		f.cancel()
+		f.cancel = nil
 	}
-
-	return lines
 }
 
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
-	return err == nil && !info.IsDir()
+func (f pendingFetch) pending() bool { return f.cancel != nil }
+
+// typing reports whether keys go to a text input, such as the step list's
+// name filter, rather than to the mode's bindings.
+func (m model) typing() bool {
+	if m.keys.group(m.mode).textual {
+		return true
+	}
+	return m.mode == modeSteps && m.session.wf != nil && m.session.steps.Filtering()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -208,19 +273,85 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.height = msg.Height - 10
 		m.width = msg.Width
+		m.help.Width = msg.Width
+		if m.session.wf != nil {
+			m.session.steps.SetSize(msg.Width, msg.Height-2)
+		}
+	case workflowLoadedMsg, stepLogsMsg, containersMsg:
+		return m.updateSteps(msg)
+	case compareLoadedMsg, compareLogsMsg:
+		return m.updateCompare(msg)
+	case fetchProgressMsg:
+		return m.updateProgress(msg)
+	case podsLoadedMsg, namespacesMsg, podLogsMsg:
+		return m.updatePods(msg)
+	case workflowsLoadedMsg:
+		return m.updateBrowse(msg)
+	case streamLinesMsg, streamEndMsg, stepFinishedMsg:
+		if m.pods.kube != nil {
+			return m.updatePods(msg)
+		}
+		return m.updateStream(msg)
+	case tea.KeyMsg:
+		if m.showHelp {
+			// Any key closes the overlay.
+			m.showHelp = false
+			return m, nil
+		}
+		if !m.typing() && key.Matches(msg, m.keys.Help) {
+			m.showHelp = true
+			return m, nil
+		}
 	}
 	switch m.mode {
+	case modeSteps:
+		return m.updateSteps(msg)
+
+	case modeContainers:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateContainers(msg)
+		}
+
+	case modeSummary:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateSummary(msg)
+		}
+
+	case modeCompare:
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return m.updateCompare(msg)
+		}
+
+	case modePods:
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return m.updatePods(msg)
+		}
+
+	case modeBrowse:
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return m.updateBrowse(msg)
+		}
+
+	case modeBrowseEdit:
+		return m.updateBrowseEdit(msg)
+
+	case modeColumns:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updateColumnPicker(msg)
+		}
+
 	case modeFullDetail:
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
-			switch msg.String() {
-			case "q", "esc":
+			k := m.keys.Detail
+			switch {
+			case key.Matches(msg, k.Back):
 				m.mode = modeView
-			case "up":
+			case key.Matches(msg, k.Up):
 				if m.detailOffset > 0 {
 					m.detailOffset--
 				}
-			case "down":
+			case key.Matches(msg, k.Down):
 				if m.detailOffset < len(m.fullDetailLines)-(m.height-4) {
 					m.detailOffset++
 				}
@@ -233,15 +364,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
-			switch msg.String() {
-			case "enter":
+			switch {
+			case key.Matches(msg, m.keys.Regex.Apply):
 				input := m.regexInput.Value()
 				m.excludePatterns = compileRegexList(input)
 				m.mode = modeView
 				m.cursor = 0
 				m.offset = 0
 				return m, nil
-			case "esc", "ctrl+c":
+			case key.Matches(msg, m.keys.Regex.Cancel):
 				m.mode = modeView
 				return m, nil
 			}
@@ -254,8 +385,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
-			switch msg.String() {
-			case "enter":
+			switch {
+			case key.Matches(msg, m.keys.Paste.Done):
 				input := m.textarea.Value()
 				trimmed := strings.TrimSpace(input)
 
@@ -267,7 +398,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.textarea.SetValue("")
 						return m, nil
 					}
-					parsed := parseLogs(string(content))
+					parsed := parseLogs(string(content), m.fields)
 					if len(parsed) == 0 {
 						m.textarea.Placeholder = "⚠️ File has no valid logs."
 						m.textarea.SetValue("")
@@ -279,7 +410,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				// 🧾 Normal input path
-				parsed := parseLogs(input)
+				parsed := parseLogs(input, m.fields)
 				if len(parsed) == 0 {
 					m.textarea.Placeholder = "⚠️ No valid logs found. Try again."
 					m.textarea.SetValue("")
@@ -288,46 +419,45 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.logs = parsed
 				m.mode = modeView
 				return m, nil
-			case "ctrl+z":
+			case key.Matches(msg, m.keys.Paste.Clear):
 				m.textarea.SetValue("")
-			case "ctrl+c", "esc":
+			case key.Matches(msg, m.keys.Paste.Quit):
 				return m, tea.Quit
 			}
 		}
 		return m, cmd
 
 	case modeView:
-		switch key := msg.(type) {
+		switch msg := msg.(type) {
 		case tea.KeyMsg:
-			switch key.String() {
-			case "ctrl+c", "q":
+			k := m.keys.View
+			switch {
+			case key.Matches(msg, k.Quit):
 				return m, tea.Quit
-			case "up":
+			case key.Matches(msg, k.Up):
 				m.scrollUp()
-			case "down":
+				m.follow = false
+			case key.Matches(msg, k.Down):
 				m.scrollDown()
-			case "enter", " ":
+			case key.Matches(msg, k.Expand):
 				logs := m.pagedLogs()
 				if len(logs) > m.cursor {
 					i := m.findLogIndex(logs[m.cursor])
 					m.logs[i].Expanded = !m.logs[i].Expanded
 				}
-			case "home", "g":
+			case key.Matches(msg, k.Top):
+				m.follow = false
 				m.offset = 0
 				m.cursor = 0
 
-			case "end", "G":
-				logCount := len(m.filteredLogs())
-				pageSize := m.pageSize()
-
-				if logCount > pageSize {
-					m.offset = logCount - pageSize
-					m.cursor = pageSize - 1
-				} else {
-					m.offset = 0
-					m.cursor = logCount - 1
+			case key.Matches(msg, k.Bottom):
+				m.scrollToBottom()
+			case key.Matches(msg, k.Follow):
+				m.follow = !m.follow
+				if m.follow {
+					m.scrollToBottom()
 				}
-			case "v":
+			case key.Matches(msg, k.Detail):
 				logs := m.pagedLogs()
 				if len(logs) == 0 || m.cursor >= len(logs) {
 					return m, nil
@@ -337,11 +467,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if len(log.Details) == 0 {
 					return m, nil
 				}
-				lines := renderStyledJSONLines(log.Details, m.width)
+				lines := m.theme.renderJSONLines(log.Details, "  ", m.width)
 				m.fullDetailLines = lines
 				m.detailOffset = 0
 				m.mode = modeFullDetail
-			case "e", "w", "i", "d", "a", "r":
+			case key.Matches(msg, k.FilterError, k.FilterWarn, k.FilterInfo, k.FilterDebug, k.FilterAll, k.Exclude):
 				m.cursor = 0
 				m.offset = 0
 
@@ -350,25 +480,72 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.logs[i].Expanded = false
 				}
 
-				switch key.String() {
-				case "e":
+				switch {
+				case key.Matches(msg, k.FilterError):
 					m.filter = "ERROR"
-				case "w":
+				case key.Matches(msg, k.FilterWarn):
 					m.filter = "WARN"
-				case "i":
+				case key.Matches(msg, k.FilterInfo):
 					m.filter = "INFO"
-				case "d":
+				case key.Matches(msg, k.FilterDebug):
 					m.filter = "DEBUG"
-				case "a":
+				case key.Matches(msg, k.FilterAll):
 					m.filter = ""
 					m.excludePatterns = nil
-				case "r":
+				case key.Matches(msg, k.Exclude):
 					m.mode = modeRegexFilter
 					m.regexInput.Focus()
 					m.regexInput.SetValue("")
 				}
 
-			case "z":
+			case key.Matches(msg, k.Split):
+				m.split = (m.split + 1) % 3
+				m.cursor = min(m.cursor, max(0, len(m.pagedLogs())-1))
+			case key.Matches(msg, k.Shrink):
+				m.resizeSplit(-5)
+				m.cursor = min(m.cursor, max(0, len(m.pagedLogs())-1))
+			case key.Matches(msg, k.Grow):
+				m.resizeSplit(5)
+
+			case key.Matches(msg, k.Columns):
+				m.pickerItems = newPickerItems(m.columns, m.logs)
+				m.pickerCursor = 0
+				m.mode = modeColumns
+
+			case key.Matches(msg, k.Steps):
+				if m.pods.kube != nil {
+					m.mode = modePods
+					return m, nil
+				}
+				if m.session.wf == nil {
+					return m, nil
+				}
+				m.enterSteps()
+			case key.Matches(msg, k.ToggleStep):
+				// The n-th key of the binding toggles the n-th step.
+				for i, bound := range k.ToggleStep.Keys() {
+					if msg.String() == bound {
+						m.toggleStep(i + 1)
+					}
+				}
+
+			case key.Matches(msg, k.Cancel):
+				if m.session.pending() {
+					m.session.end()
+					m.statusMessage = "⏹ Cancelling…"
+				}
+				if len(m.session.streams) > 0 {
+					m.stopStreams()
+					m.statusMessage = "⏹ Stopped following"
+				}
+				if len(m.pods.streams) > 0 {
+					m.pods.endFetch()
+					m.statusMessage = "⏹ Stopped following"
+				}
+			case key.Matches(msg, k.Back):
+				m.session.end()
+				m.stopStreams()
+				m.pods.endFetch()
 				m.textarea.SetValue("")
 				m.mode = modePaste
 			}
@@ -377,3 +554,54 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	return m, nil
 }
+
+func (m model) updateColumnPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	items := m.pickerItems
+	c := m.pickerCursor
+	k := m.keys.Columns
+	switch {
+	case key.Matches(msg, k.Up):
+		if c > 0 {
+			m.pickerCursor--
+		}
+	case key.Matches(msg, k.Down):
+		if c < len(items)-1 {
+			m.pickerCursor++
+		}
+	case key.Matches(msg, k.MoveUp):
+		if c > 0 {
+			items[c], items[c-1] = items[c-1], items[c]
+			m.pickerCursor--
+		}
+	case key.Matches(msg, k.MoveDown):
+		if c < len(items)-1 {
+			items[c], items[c+1] = items[c+1], items[c]
+			m.pickerCursor++
+		}
+	case key.Matches(msg, k.Narrower):
+		if items[c].Width > 0 {
+			items[c].Width--
+		}
+	case key.Matches(msg, k.Wider):
+		items[c].Width++
+	case key.Matches(msg, k.Toggle):
+		items[c].Enabled = !items[c].Enabled
+	case key.Matches(msg, k.Apply):
+		columns := pickerColumns(items)
+		if len(columns) == 0 {
+			m.statusMessage = "⚠️ Select at least one column."
+			return m, nil
+		}
+		m.columns = columns
+		m.cfg.Columns = columns
+		m.statusMessage = ""
+		if err := saveConfig(m.cfgPath, m.cfg); err != nil {
+			m.statusMessage = "⚠️ Columns applied but not saved: " + err.Error()
+		}
+		m.mode = modeView
+	case key.Matches(msg, k.Cancel):
+		m.statusMessage = ""
+		m.mode = modeView
+	}
+	return m, nil
+}
@@ -11,14 +11,77 @@ type logEntry struct {
 	Timestamp string                 `json:"timestamp"`
 	Message   string                 `json:"message"`
 	Details   map[string]interface{} `json:"-"`
+	Fields    map[string]interface{} `json:"-"` // every raw field, including hidden ones like traceId
+	Step      string                 `json:"-"` // Argo step the entry came from
+	Container string                 `json:"-"` // and its container
+	Pod       string                 `json:"-"` // Kubernetes pod the entry came from
+	Source    string                 `json:"-"` // stepSource key, for per-source visibility
 	Expanded  bool
 }
 
-func parseLogs(input string) []logEntry {
+// fieldMapping names the raw keys that hold the level, timestamp and message
+// (the first key present wins) and the keys left out of the details view.
+type fieldMapping struct {
+	Level     []string `json:"level,omitempty"`
+	Timestamp []string `json:"timestamp,omitempty"`
+	Message   []string `json:"message,omitempty"`
+	Hidden    []string `json:"hidden,omitempty"`
+}
+
+var defaultFieldMapping = fieldMapping{
+	Level:     []string{"level"},
+	Timestamp: []string{"timestamp"},
+	Message:   []string{"message"},
+	Hidden:    []string{"jobName", "traceId", "requestId", "workflowId", "currentExecutedFlow"},
+}
+
+// withDefaults fills every unset list from defaultFieldMapping.
+func (f fieldMapping) withDefaults() fieldMapping {
+	if len(f.Level) == 0 {
+		f.Level = defaultFieldMapping.Level
+	}
+	if len(f.Timestamp) == 0 {
+		f.Timestamp = defaultFieldMapping.Timestamp
+	}
+	if len(f.Message) == 0 {
+		f.Message = defaultFieldMapping.Message
+	}
+	if f.Hidden == nil {
+		f.Hidden = defaultFieldMapping.Hidden
+	}
+	return f
+}
+
+func firstField(raw map[string]interface{}, keys []string) interface{} {
+	for _, k := range keys {
+		if v, ok := raw[k]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func parseLogs(input string, fields fieldMapping) []logEntry {
 	lines := strings.Split(input, "\n")
 	var logs []logEntry
 
+	skip := map[string]bool{}
+	for _, keys := range [][]string{fields.Level, fields.Timestamp, fields.Message, fields.Hidden} {
+		for _, k := range keys {
+			skip[k] = true
+		}
+	}
+
+	partial := envelopes{}
 	for _, line := range lines {
+		// CRI payloads keep their spaces, a partial line may end in one.
+		if env, ok := unwrapEnvelope(strings.TrimLeft(strings.TrimRight(line, "\r"), " \t")); ok {
+			if env, ok = partial.add(env); ok {
+				logs = append(logs, parseEnvelope(env, fields, skip))
+			}
+			continue
+		}
+
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
@@ -28,24 +91,49 @@ func parseLogs(input string) []logEntry {
 		if err := json.Unmarshal([]byte(line), &raw); err != nil {
 			continue
 		}
+		logs = append(logs, parseEntry(raw, fields, skip))
+	}
+	for _, env := range partial.rest() {
+		logs = append(logs, parseEnvelope(env, fields, skip))
+	}
+	return logs
+}
 
-		log := logEntry{
-			Level:     fmt.Sprintf("%v", raw["level"]),
-			Timestamp: fmt.Sprintf("%v", raw["timestamp"]),
-			Message:   fmt.Sprintf("%v", raw["message"]),
-			Details:   make(map[string]interface{}),
-		}
+func parseEntry(raw map[string]interface{}, fields fieldMapping, skip map[string]bool) logEntry {
+	log := logEntry{
+		Level:     fmt.Sprintf("%v", firstField(raw, fields.Level)),
+		Timestamp: fmt.Sprintf("%v", firstField(raw, fields.Timestamp)),
+		Message:   fmt.Sprintf("%v", firstField(raw, fields.Message)),
+		Details:   make(map[string]interface{}),
+		Fields:    raw,
+	}
 
-		for k, v := range raw {
-			switch k {
-			case "level", "timestamp", "message", "jobName", "traceId", "requestId", "workflowId", "currentExecutedFlow":
-				continue
-			default:
-				log.Details[k] = v
-			}
+	for k, v := range raw {
+		if !skip[k] {
+			log.Details[k] = v
 		}
+	}
+	return log
+}
 
-		logs = append(logs, log)
+// parseEnvelope parses a runtime envelope's payload like any other line and
+// keeps the stream and runtime time under "runtime". The runtime time stands
+// in for a missing timestamp, and a payload that is not JSON, such as a stack
+// trace on stderr, becomes a message of its own rather than being dropped.
+func parseEnvelope(env envelope, fields fieldMapping, skip map[string]bool) logEntry {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(env.payload)), &raw); err != nil || raw == nil {
+		raw = map[string]interface{}{fields.Message[0]: env.payload}
 	}
-	return logs
+	if _, ok := raw["runtime"]; !ok {
+		raw["runtime"] = env.metadata()
+	}
+	log := parseEntry(raw, fields, skip)
+	if firstField(raw, fields.Timestamp) == nil {
+		log.Timestamp = env.time
+	}
+	if firstField(raw, fields.Level) == nil {
+		log.Level = ""
+	}
+	return log
 }
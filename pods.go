@@ -0,0 +1,529 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"logviewer-tui/argo"
+	"logviewer-tui/kube"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// podSession is the Kubernetes log source: the containers of the pods
+// matching a label selector, fetched or followed together and merged with
+// a pod column.
+type podSession struct {
+	kube      *kube.Client
+	namespace string // "" for every namespace
+	selector  string
+	rows      []podRow
+	marked    map[string]bool // keyed by stepSource.key
+	cursor    int
+	sources   []stepSource          // shown in the viewer
+	streams   map[string]*logStream // keyed by stepSource.key, while following
+	following bool                  // sources were followed rather than downloaded
+	tailStep  int                   // index into podTails
+	status    string
+
+	// namespaces is the namespace choice, open while not nil; its first
+	// row stands for every namespace.
+	namespaces []string
+	nsCursor   int
+
+	// pendingFetch is the pending listing, download or follow, ended by the
+	// cancel key or the next request.
+	pendingFetch
+}
+
+// podTails are the log lengths, in lines, the more key steps through; 0 is
+// the whole log. Long-lived pods can have written hundreds of megabytes.
+var podTails = []int{1000, 10000, 100000, 0}
+
+func (p podSession) tail() int { return podTails[p.tailStep] }
+
+// history says how much of each log is fetched, e.g. "last 1000 lines".
+func (p podSession) history() string {
+	if p.tail() == 0 {
+		return "whole logs"
+	}
+	return fmt.Sprintf("last %d lines", p.tail())
+}
+
+// podRow is one container of a listed pod.
+type podRow struct {
+	pod       kube.Pod
+	container string
+	init      bool
+}
+
+func (r podRow) source() stepSource {
+	return stepSource{nodeID: r.pod.Namespace + "/" + r.pod.Name, container: r.container}
+}
+
+type podsLoadedMsg struct {
+	fetch context.Context
+	pods  []kube.Pod
+	err   error
+}
+
+type namespacesMsg struct {
+	fetch context.Context
+	names []string
+	err   error
+}
+
+// podLogsMsg carries the downloaded logs of the sources to show, keyed by
+// stepSource.key.
+type podLogsMsg struct {
+	fetch   context.Context
+	sources []stepSource
+	logs    map[string]string
+	err     error
+}
+
+// openPods starts the viewer on the pod picker.
+func (m *model) openPods(client *kube.Client, namespace, selector string) {
+	m.pods = podSession{
+		kube:      client,
+		namespace: namespace,
+		selector:  selector,
+		marked:    map[string]bool{},
+		streams:   map[string]*logStream{},
+	}
+	m.mode = modePods
+	m.pods.status = "⏳ Listing pods…"
+	m.pods.newFetch()
+}
+
+// newFetch starts a request, stopping the previous one and any followed
+// streams.
+func (p *podSession) newFetch() {
+	p.endFetch()
+	p.start()
+}
+
+// endFetch ends the pending request along with the streams it follows.
+func (p *podSession) endFetch() {
+	p.end()
+	p.streams = map[string]*logStream{}
+}
+
+// scope names the selector and namespace, e.g. "app=api in shop".
+func (p podSession) scope() string {
+	selector, namespace := p.selector, p.namespace
+	if selector == "" {
+		selector = "all pods"
+	}
+	if namespace == "" {
+		namespace = "all namespaces"
+	}
+	return selector + " in " + namespace
+}
+
+func (m model) loadPods() tea.Cmd {
+	kube, ctx, namespace, selector := m.pods.kube, m.pods.ctx, m.pods.namespace, m.pods.selector
+	return func() tea.Msg {
+		pods, err := kube.ListPods(ctx, namespace, selector)
+		return podsLoadedMsg{fetch: ctx, pods: pods, err: err}
+	}
+}
+
+func (m model) loadNamespaces() tea.Cmd {
+	kube, ctx := m.pods.kube, m.pods.ctx
+	return func() tea.Msg {
+		names, err := kube.ListNamespaces(ctx)
+		return namespacesMsg{fetch: ctx, names: names, err: err}
+	}
+}
+
+// loadPodLogs downloads the tail of the sources' logs concurrently.
+func (m model) loadPodLogs(sources []stepSource) tea.Cmd {
+	kube, ctx, tail := m.pods.kube, m.pods.ctx, m.pods.tail()
+	return func() tea.Msg {
+		var (
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+			logs = map[string]string{}
+			errs []error
+		)
+		for _, src := range sources {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				namespace, pod, _ := strings.Cut(src.nodeID, "/")
+				out, err := kube.TailPodLogs(ctx, namespace, pod, src.container, tail)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s/%s: %w", pod, src.container, err))
+					return
+				}
+				logs[src.key()] = out
+			}()
+		}
+		wg.Wait()
+		return podLogsMsg{fetch: ctx, sources: sources, logs: logs, err: errors.Join(errs...)}
+	}
+}
+
+// setPods lists the pods' containers, keeping the marks of those still
+// there; without any, each pod's first container is marked.
+func (m *model) setPods(pods []kube.Pod) {
+	var rows []podRow
+	for _, pod := range pods {
+		for _, name := range pod.Containers {
+			rows = append(rows, podRow{pod: pod, container: name})
+		}
+		for _, name := range pod.InitContainers {
+			rows = append(rows, podRow{pod: pod, container: name, init: true})
+		}
+	}
+	marked := map[string]bool{}
+	for _, row := range rows {
+		if m.pods.marked[row.source().key()] {
+			marked[row.source().key()] = true
+		}
+	}
+	if len(marked) == 0 {
+		for _, pod := range pods {
+			if len(pod.Containers) > 0 {
+				marked[podRow{pod: pod, container: pod.Containers[0]}.source().key()] = true
+			}
+		}
+	}
+	m.pods.rows = rows
+	m.pods.marked = marked
+	m.pods.cursor = min(m.pods.cursor, max(0, len(rows)-1))
+}
+
+// selectedSources are the marked containers in list order, or the one
+// under the cursor when none is marked.
+func (m model) selectedSources() []stepSource {
+	var sources []stepSource
+	for _, row := range m.pods.rows {
+		if m.pods.marked[row.source().key()] {
+			sources = append(sources, row.source())
+		}
+	}
+	if len(sources) == 0 && len(m.pods.rows) > 0 {
+		sources = append(sources, m.pods.rows[m.pods.cursor].source())
+	}
+	return sources
+}
+
+// parsePod parses a pod container's raw logs and tags every entry with it.
+func (m model) parsePod(src stepSource, logs string) []logEntry {
+	_, pod, _ := strings.Cut(src.nodeID, "/")
+	parsed := parseLogs(logs, m.fields)
+	for i := range parsed {
+		parsed[i].Pod = pod
+		parsed[i].Container = src.container
+		parsed[i].Source = src.key()
+	}
+	return parsed
+}
+
+// podLabels name the shown sources by pod, adding the container when they
+// are not all the same one.
+func (m model) podLabels() []string {
+	containers := map[string]bool{}
+	for _, src := range m.pods.sources {
+		containers[src.container] = true
+	}
+	labels := make([]string, len(m.pods.sources))
+	for i, src := range m.pods.sources {
+		_, labels[i], _ = strings.Cut(src.nodeID, "/")
+		if len(containers) > 1 {
+			labels[i] += "/" + src.container
+		}
+	}
+	return labels
+}
+
+// showPods puts the given sources' entries in the viewer.
+func (m *model) showPods(sources []stepSource, logs []logEntry) {
+	m.pods.sources = sources
+	m.logs = logs
+	m.cursor = 0
+	m.offset = 0
+	m.hiddenSteps = map[string]bool{}
+	m.pods.status = ""
+	m.mode = modeView
+}
+
+// followPods streams the sources' logs into the viewer, starting with their
+// tail.
+func (m *model) followPods(sources []stepSource) tea.Cmd {
+	m.pods.newFetch()
+	kube, ctx, tail := m.pods.kube, m.pods.ctx, m.pods.tail()
+	var cmds []tea.Cmd
+	for _, src := range sources {
+		namespace, pod, _ := strings.Cut(src.nodeID, "/")
+		s := pumpStream(ctx, src, func(ctx context.Context) (lineStream, error) {
+			return kube.StreamPodLogs(ctx, namespace, pod, src.container, tail)
+		})
+		m.pods.streams[src.key()] = s
+		cmds = append(cmds, s.wait())
+	}
+	m.showPods(sources, nil)
+	m.pods.following = true
+	m.follow = true
+	m.statusMessage = ""
+	return tea.Batch(cmds...)
+}
+
+func (m model) updatePods(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case podsLoadedMsg:
+		if msg.fetch != m.pods.ctx {
+			return m, nil
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.pods.status = "⏹ Cancelled."
+			return m, nil
+		}
+		m.pods.endFetch()
+		if msg.err != nil {
+			m.pods.status = "❌ Failed to list pods: " + msg.err.Error()
+			return m, nil
+		}
+		m.setPods(msg.pods)
+		m.pods.status = ""
+		if len(msg.pods) == 0 {
+			m.pods.status = "No pods match " + m.pods.scope()
+		}
+		return m, nil
+
+	case namespacesMsg:
+		if msg.fetch != m.pods.ctx {
+			return m, nil
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.pods.status = "⏹ Cancelled."
+			return m, nil
+		}
+		m.pods.endFetch()
+		if msg.err != nil {
+			m.pods.status = "❌ Failed to list namespaces: " + msg.err.Error()
+			return m, nil
+		}
+		m.pods.namespaces = append([]string{""}, msg.names...)
+		m.pods.nsCursor = 0
+		for i, name := range m.pods.namespaces {
+			if name == m.pods.namespace {
+				m.pods.nsCursor = i
+			}
+		}
+		m.pods.status = ""
+		return m, nil
+
+	case podLogsMsg:
+		if msg.fetch != m.pods.ctx {
+			return m, nil
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.pods.status = "⏹ Cancelled."
+			return m, nil
+		}
+		m.pods.endFetch()
+		if len(msg.logs) == 0 {
+			m.pods.status = "❌ Failed to fetch logs: " + msg.err.Error()
+			return m, nil
+		}
+		var parsed [][]logEntry
+		for _, src := range msg.sources {
+			parsed = append(parsed, m.parsePod(src, msg.logs[src.key()]))
+		}
+		m.showPods(msg.sources, mergeSteps(parsed))
+		m.pods.following = false
+		m.follow = false
+		m.statusMessage = ""
+		if msg.err != nil {
+			m.statusMessage = "⚠️ Some containers failed: " + msg.err.Error()
+		}
+		return m, nil
+
+	case streamLinesMsg:
+		if m.pods.streams[msg.src.key()] != msg.stream {
+			return m, nil // stopped
+		}
+		m.logs = appendSorted(m.logs, m.parsePod(msg.src, strings.Join(msg.lines, "\n")), m.merged())
+		if m.follow {
+			m.scrollToBottom()
+		}
+		return m, msg.stream.wait()
+
+	case streamEndMsg:
+		if m.pods.streams[msg.src.key()] != msg.stream {
+			return m, nil
+		}
+		delete(m.pods.streams, msg.src.key())
+		if len(m.pods.streams) == 0 {
+			m.pods.endFetch()
+		}
+		_, pod, _ := strings.Cut(msg.src.nodeID, "/")
+		if msg.err != nil {
+			m.statusMessage = "⚠️ Log stream of " + pod + " failed: " + msg.err.Error()
+		} else {
+			m.statusMessage = "⏹ " + pod + "/" + msg.src.container + " exited"
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.pods.namespaces != nil {
+			return m.updateNamespaceChoice(msg)
+		}
+		k := m.keys.Pods
+		if m.pods.pending() && len(m.pods.streams) == 0 && key.Matches(msg, k.Cancel, k.Back) {
+			m.pods.endFetch()
+			m.pods.status = "⏹ Cancelling…"
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, k.Up):
+			if m.pods.cursor > 0 {
+				m.pods.cursor--
+			}
+		case key.Matches(msg, k.Down):
+			if m.pods.cursor < len(m.pods.rows)-1 {
+				m.pods.cursor++
+			}
+		case key.Matches(msg, k.Mark):
+			if len(m.pods.rows) > 0 {
+				src := m.pods.rows[m.pods.cursor].source().key()
+				m.pods.marked[src] = !m.pods.marked[src]
+			}
+		case key.Matches(msg, k.Open, k.Follow):
+			sources := m.selectedSources()
+			if len(sources) == 0 {
+				return m, nil
+			}
+			if key.Matches(msg, k.Follow) {
+				return m, m.followPods(sources)
+			}
+			m.pods.newFetch()
+			m.pods.status = fmt.Sprintf("📡 Fetching logs of %d containers…", len(sources))
+			return m, m.loadPodLogs(sources)
+		case key.Matches(msg, k.More):
+			if m.pods.tail() == 0 {
+				m.pods.status = "Already showing whole logs"
+				return m, nil
+			}
+			m.pods.tailStep++
+			// Fetch what the viewer shows again, with the longer history.
+			switch sources := m.pods.sources; {
+			case len(sources) == 0:
+				m.pods.status = "Logs will show the " + m.pods.history()
+			case m.pods.following:
+				return m, m.followPods(sources)
+			default:
+				m.pods.newFetch()
+				m.pods.status = fmt.Sprintf("📡 Fetching the %s of %d containers…", m.pods.history(), len(sources))
+				return m, m.loadPodLogs(sources)
+			}
+		case key.Matches(msg, k.Namespace):
+			m.pods.newFetch()
+			m.pods.status = "⏳ Listing namespaces…"
+			return m, m.loadNamespaces()
+		case key.Matches(msg, k.Refresh):
+			m.pods.newFetch()
+			m.pods.status = "⏳ Listing pods…"
+			return m, m.loadPods()
+		case key.Matches(msg, k.Back):
+			if len(m.pods.sources) > 0 {
+				m.mode = modeView
+			}
+		case key.Matches(msg, k.Quit):
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateNamespaceChoice(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := m.keys.Pods
+	switch {
+	case key.Matches(msg, k.Up):
+		if m.pods.nsCursor > 0 {
+			m.pods.nsCursor--
+		}
+	case key.Matches(msg, k.Down):
+		if m.pods.nsCursor < len(m.pods.namespaces)-1 {
+			m.pods.nsCursor++
+		}
+	case key.Matches(msg, k.Open):
+		m.pods.namespace = m.pods.namespaces[m.pods.nsCursor]
+		m.pods.namespaces = nil
+		m.pods.cursor = 0
+		m.pods.newFetch()
+		m.pods.status = "⏳ Listing pods…"
+		return m, m.loadPods()
+	case key.Matches(msg, k.Back):
+		m.pods.namespaces = nil
+	}
+	return m, nil
+}
+
+// viewPods renders the pod picker, or the namespace choice while open.
+func (m model) viewPods(footer string) string {
+	var title string
+	var lines []string
+	selected := 0
+	if m.pods.namespaces != nil {
+		title = "☸️  Namespace"
+		for _, name := range m.pods.namespaces {
+			if name == "" {
+				name = "all namespaces"
+			}
+			lines = append(lines, name)
+		}
+		selected = m.pods.nsCursor
+	} else {
+		title = "☸️  Pods — " + m.pods.scope() + " · " + m.pods.history()
+		for _, row := range m.pods.rows {
+			mark := "[ ]"
+			if m.pods.marked[row.source().key()] {
+				mark = "[x]"
+			}
+			name := row.pod.Name
+			if m.pods.namespace == "" {
+				name = row.pod.Namespace + "/" + name
+			}
+			container := row.container
+			if row.init {
+				container += " (init)"
+			}
+			age := ""
+			if !row.pod.StartedAt.IsZero() {
+				age = argo.FormatDuration(time.Since(row.pod.StartedAt))
+			}
+			lines = append(lines, fmt.Sprintf("%s %-40s %-20s %-10s %s", mark, name, container, row.pod.Phase, m.theme.Hint.Render(age)))
+		}
+		selected = m.pods.cursor
+	}
+
+	visible := max(1, m.height-4)
+	start := 0
+	if selected >= visible {
+		start = selected - visible + 1
+	}
+	var b strings.Builder
+	for i := start; i < len(lines) && i < start+visible; i++ {
+		prefix := "  "
+		if i == selected {
+			prefix = "> "
+		}
+		b.WriteString(prefix + lines[i] + "\n")
+	}
+	status := ""
+	if m.pods.status != "" {
+		status = m.pods.status + "\n"
+	}
+	return m.theme.Title.Render(title) + "\n\n" + b.String() + "\n" + status + footer
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	splitNone int = iota
+	splitSideBySide
+	splitTopBottom
+)
+
+const (
+	defaultSplitRatio = 50
+	minSplitRatio     = 20
+	maxSplitRatio     = 80
+)
+
+// listHeight is the number of lines available for the log list.
+func (m model) listHeight() int {
+	h := m.height - 4 // room for header + footer
+	if m.split == splitTopBottom {
+		h = h * m.splitRatio / 100
+	}
+	return max(1, h)
+}
+
+// listWidth is the width of the log list, or 0 when the terminal width is
+// still unknown.
+func (m model) listWidth() int {
+	if m.split == splitSideBySide && m.width > 0 {
+		return max(1, m.width*m.splitRatio/100)
+	}
+	return m.width
+}
+
+func (m *model) resizeSplit(delta int) {
+	m.splitRatio = min(maxSplitRatio, max(minSplitRatio, m.splitRatio+delta))
+}
+
+func (m model) selectedLog() (logEntry, bool) {
+	logs := m.pagedLogs()
+	if m.cursor < 0 || m.cursor >= len(logs) {
+		return logEntry{}, false
+	}
+	return logs[m.cursor], true
+}
+
+// renderPreview renders the selected entry's details for the second pane.
+func (m model) renderPreview() string {
+	border := lipgloss.NewStyle().BorderForeground(m.theme.Border.GetForeground())
+	var width, height int
+	switch m.split {
+	case splitSideBySide:
+		width = max(1, m.width-m.listWidth()-2)
+		height = max(1, m.height-4)
+		border = border.Border(lipgloss.NormalBorder(), false, false, false, true).PaddingLeft(1)
+	default:
+		width = max(1, m.width)
+		height = max(1, m.height-4-m.listHeight()-1)
+		border = border.Border(lipgloss.NormalBorder(), true, false, false, false)
+	}
+
+	var b strings.Builder
+	log, ok := m.selectedLog()
+	if !ok {
+		b.WriteString(m.theme.Hint.Render("No entry selected."))
+	} else {
+		level := strings.ToUpper(log.Level)
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render(log.Timestamp) + " " + m.theme.level(level).Render(level) + "\n")
+		b.WriteString(log.Message + "\n")
+		if len(log.Details) > 0 {
+			b.WriteString("\n" + m.theme.renderJSON(log.Details))
+		}
+	}
+
+	content := lipgloss.NewStyle().Width(width).Render(b.String())
+	lines := strings.Split(content, "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return border.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,616 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"logviewer-tui/argo"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// workflowSession is the Argo workflow whose steps the viewer switches
+// between. The viewer shows one step container, or several merged by
+// timestamp; each selection keeps its own position and hidden sources.
+type workflowSession struct {
+	client  *argo.Client
+	name    string
+	wf      *argo.Workflow
+	steps   argo.StepList
+	sources []stepSource          // shown in the viewer
+	parsed  map[string][]logEntry // keyed by stepSource.key
+	views   map[string]stepView   // keyed by viewKey
+	streams map[string]*logStream // keyed by stepSource.key
+	choice  containerChoice
+	status  string
+
+	summaryCursor int // selected step of the summary screen
+
+	// compare is the baseline run the compare screen diffs steps against.
+	compareName string
+	compare     *argo.Workflow
+	compareErr  error // why compare failed to load, retried on the next compare
+	comparison  compareState
+
+	// progress follows the log downloads of the pending fetch.
+	progress *fetchProgress
+
+	// pendingFetch is the user's pending request, ended by the cancel key.
+	pendingFetch
+
+	// stopLive ends the streams of the shown running steps once they leave
+	// the viewer.
+	stopLive context.CancelFunc
+}
+
+// stepSource is one container of a step; unless the user picks another
+// container it is "main". Sources of the Kubernetes pod picker use the same
+// type, with nodeID set to namespace/pod.
+type stepSource struct {
+	nodeID    string
+	container string
+}
+
+func (s stepSource) key() string { return s.nodeID + "/" + s.container }
+
+// containerChoice is the container picker of one step. The last row, past
+// the container names, merges them all.
+type containerChoice struct {
+	nodeID string
+	names  []string
+	cursor int
+}
+
+type stepView struct {
+	logs   []logEntry
+	cursor int
+	offset int
+	hidden map[string]bool
+}
+
+type workflowLoadedMsg struct {
+	fetch context.Context
+	wf    *argo.Workflow
+	err   error
+}
+
+// stepLogsMsg carries the logs fetched for the sources to show, keyed by
+// stepSource.key; sources already parsed are not fetched again.
+type stepLogsMsg struct {
+	sources  []stepSource
+	logs     map[string]string
+	err      error
+	progress *fetchProgress
+}
+
+type containersMsg struct {
+	nodeID string
+	names  []string
+	err    error
+}
+
+func viewKey(sources []stepSource) string {
+	keys := make([]string, len(sources))
+	for i, src := range sources {
+		keys[i] = src.key()
+	}
+	return strings.Join(keys, ",")
+}
+
+// openWorkflow starts the step picker for a workflow, loading it first
+// unless wf is already known.
+func (m *model) openWorkflow(client *argo.Client, name string, wf *argo.Workflow) {
+	m.session = workflowSession{
+		client:  client,
+		name:    name,
+		parsed:  map[string][]logEntry{},
+		views:   map[string]stepView{},
+		streams: map[string]*logStream{},
+	}
+	m.mode = modeSteps
+	if wf != nil {
+		m.setWorkflow(wf)
+	} else {
+		m.session.status = "⏳ Loading workflow " + name + "…"
+		m.session.start()
+	}
+}
+
+// stopStreams ends the live streams and drops what they received, so that
+// opening their steps again follows them from the start.
+func (m *model) stopStreams() {
+	if m.session.stopLive != nil {
+		m.session.stopLive()
+		m.session.stopLive = nil
+	}
+	for k, s := range m.session.streams {
+		delete(m.session.parsed, k)
+		for vk := range m.session.views {
+			if inView(vk, s.src) {
+				delete(m.session.views, vk)
+			}
+		}
+	}
+	m.session.streams = map[string]*logStream{}
+}
+
+func (m *model) setWorkflow(wf *argo.Workflow) {
+	m.session.wf = wf
+	m.session.name = wf.Metadata.Name
+	m.session.steps = argo.NewStepList(wf)
+	m.session.steps.SetSize(m.width, m.height+8)
+	m.session.status = ""
+}
+
+func (m model) loadWorkflow() tea.Cmd {
+	client, name, ctx := m.session.client, m.session.name, m.session.ctx
+	return func() tea.Msg {
+		wf, err := client.FindWorkflow(ctx, name)
+		return workflowLoadedMsg{fetch: ctx, wf: wf, err: err}
+	}
+}
+
+func (m model) loadContainers(nodeID string) tea.Cmd {
+	client, wf, ctx := m.session.client, m.session.wf, m.session.ctx
+	return func() tea.Msg {
+		names := client.NodeContainers(ctx, wf, nodeID)
+		return containersMsg{nodeID: nodeID, names: names, err: ctx.Err()}
+	}
+}
+
+// loadSources fetches the logs of the given sources that are not parsed
+// yet, concurrently, reporting the downloads to p.
+func (m model) loadSources(sources []stepSource, p *fetchProgress) tea.Cmd {
+	client, wf, ctx := m.session.client, m.session.wf, m.session.ctx
+	var missing []stepSource
+	labels := map[string]string{}
+	for _, src := range sources {
+		if _, ok := m.session.parsed[src.key()]; !ok {
+			missing = append(missing, src)
+			labels[src.key()] = m.sourceLabel(src)
+		}
+	}
+	return func() tea.Msg {
+		var (
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+			logs = map[string]string{}
+			errs []error
+		)
+		for _, src := range missing {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				out, err := client.DownloadContainerLogs(ctx, wf, src.nodeID, src.container, p.track(src))
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", labels[src.key()], err))
+					return
+				}
+				logs[src.key()] = out
+			}()
+		}
+		wg.Wait()
+		close(p.notify)
+		return stepLogsMsg{sources: sources, logs: logs, err: errors.Join(errs...), progress: p}
+	}
+}
+
+// mergeSteps combines the parsed logs of several sources in timestamp order.
+func mergeSteps(steps [][]logEntry) []logEntry {
+	var merged []logEntry
+	for _, logs := range steps {
+		merged = append(merged, logs...)
+	}
+	if len(steps) > 1 {
+		sort.SliceStable(merged, func(i, j int) bool {
+			return timestampBefore(merged[i].Timestamp, merged[j].Timestamp)
+		})
+	}
+	return merged
+}
+
+// timestampBefore compares RFC 3339 timestamps by time, and anything else
+// as text.
+func timestampBefore(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339Nano, a)
+	tb, errB := time.Parse(time.RFC3339Nano, b)
+	if errA == nil && errB == nil {
+		return ta.Before(tb)
+	}
+	return a < b
+}
+
+// saveStepView remembers the current selection's logs and position.
+func (m *model) saveStepView() {
+	if len(m.session.sources) > 0 {
+		m.session.views[viewKey(m.session.sources)] = stepView{
+			logs: m.logs, cursor: m.cursor, offset: m.offset, hidden: m.hiddenSteps,
+		}
+	}
+}
+
+func (m *model) showSources(sources []stepSource, view stepView) {
+	m.saveStepView()
+	m.session.sources = sources
+	m.logs = view.logs
+	m.cursor = view.cursor
+	m.offset = view.offset
+	m.hiddenSteps = view.hidden
+	if m.hiddenSteps == nil {
+		m.hiddenSteps = map[string]bool{}
+	}
+	m.session.status = ""
+	m.mode = modeView
+}
+
+// sourceLabel names a source by its step, adding the container unless it
+// is main.
+func (m model) sourceLabel(src stepSource) string {
+	label := m.session.wf.Status.Nodes[src.nodeID].DisplayName
+	if src.container != "main" {
+		label += "/" + src.container
+	}
+	return label
+}
+
+// sourceLabels are the labels of the sources shown in the viewer.
+func (m model) sourceLabels() []string {
+	if m.pods.kube != nil {
+		return m.podLabels()
+	}
+	if m.session.wf == nil {
+		return nil
+	}
+	labels := make([]string, len(m.session.sources))
+	for i, src := range m.session.sources {
+		labels[i] = m.sourceLabel(src)
+	}
+	return labels
+}
+
+// shownSources are the Argo step or pod containers shown in the viewer.
+func (m model) shownSources() []stepSource {
+	if m.pods.kube != nil {
+		return m.pods.sources
+	}
+	return m.session.sources
+}
+
+// merged reports whether the viewer shows several sources at once.
+func (m model) merged() bool { return len(m.shownSources()) > 1 }
+
+// toggleStep shows or hides the n-th (1-based) source of a merged view.
+func (m *model) toggleStep(n int) {
+	sources := m.shownSources()
+	if !m.merged() || n < 1 || n > len(sources) {
+		return
+	}
+	k := sources[n-1].key()
+	m.hiddenSteps[k] = !m.hiddenSteps[k]
+	m.cursor = 0
+	m.offset = 0
+}
+
+// openSources shows the given sources, from the cache when this selection
+// was viewed before and fully downloaded. Running steps have no artifact yet
+// and are followed live instead.
+func (m *model) openSources(sources []stepSource) tea.Cmd {
+	m.stopStreams()
+	if view, ok := m.session.views[viewKey(sources)]; ok && m.parsedAll(sources) {
+		m.showSources(sources, view)
+		return nil
+	}
+	if len(sources) == 1 {
+		m.session.status = "📡 Fetching logs for " + m.sourceLabel(sources[0]) + "…"
+	} else {
+		m.session.status = fmt.Sprintf("📡 Fetching logs for %d sources…", len(sources))
+	}
+	m.session.start()
+	live, stop := context.WithCancel(context.Background())
+	m.session.stopLive = stop
+	var cmds []tea.Cmd
+	for _, src := range sources {
+		if _, ok := m.session.parsed[src.key()]; ok || !running(m.session.wf.Status.Nodes[src.nodeID].Phase) {
+			continue
+		}
+		s := startStream(live, m.session.client, m.session.wf, src)
+		m.session.streams[src.key()] = s
+		m.session.parsed[src.key()] = []logEntry{}
+		cmds = append(cmds, s.wait())
+	}
+	m.session.progress = newFetchProgress(sources)
+	return tea.Batch(append(cmds, m.loadSources(sources, m.session.progress), m.session.progress.wait())...)
+}
+
+func (m model) parsedAll(sources []stepSource) bool {
+	for _, src := range sources {
+		if _, ok := m.session.parsed[src.key()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (m model) updateSteps(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case workflowLoadedMsg:
+		if msg.fetch != m.session.ctx {
+			return m, nil
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			m.session.status = "⏹ Cancelled."
+			return m, nil
+		}
+		m.session.end()
+		if msg.err != nil {
+			m.session.status = "❌ Failed to fetch workflow: " + msg.err.Error()
+			return m, nil
+		}
+		m.setWorkflow(msg.wf)
+		return m, nil
+
+	case containersMsg:
+		if msg.err != nil {
+			m.session.status = "⏹ Cancelled."
+			return m, nil
+		}
+		m.session.end()
+		m.session.choice = containerChoice{nodeID: msg.nodeID, names: msg.names}
+		m.session.status = ""
+		m.mode = modeContainers
+		return m, nil
+
+	case stepLogsMsg:
+		p := msg.progress
+		current := p == m.session.progress
+		if current {
+			m.session.progress = nil
+		}
+		if errors.Is(msg.err, context.Canceled) {
+			switch {
+			case !current:
+			case p.shown:
+				m.statusMessage = fmt.Sprintf("⏹ Download cancelled, showing the %d entries received", len(m.logs))
+			default:
+				m.session.status = "⏹ Cancelled."
+			}
+			return m, nil
+		}
+		if current {
+			m.session.end()
+		}
+		for _, src := range msg.sources {
+			if out, ok := msg.logs[src.key()]; ok {
+				m.session.parsed[src.key()] = m.parseSource(src, out)
+			}
+		}
+		var steps [][]logEntry
+		var shown []stepSource
+		live := false
+		for _, src := range msg.sources {
+			if logs, ok := m.session.parsed[src.key()]; ok {
+				steps = append(steps, logs)
+				shown = append(shown, src)
+				_, streaming := m.session.streams[src.key()]
+				live = live || streaming
+			}
+		}
+		logs := mergeSteps(steps)
+		if len(logs) == 0 && !live {
+			if msg.err != nil {
+				m.session.status = "❌ Failed to fetch logs: " + msg.err.Error()
+			} else {
+				m.session.status = "⚠️ No valid logs in the selected steps."
+			}
+			return m, nil
+		}
+		if !current {
+			return m, nil
+		}
+		if p.shown {
+			// The viewer already shows the entries received so far; keep
+			// its position.
+			m.session.sources = shown
+			m.logs = logs
+			m.cursor = min(m.cursor, max(0, len(m.pagedLogs())-1))
+		} else {
+			m.showSources(shown, stepView{logs: logs})
+		}
+		m.follow = live
+		if live {
+			m.scrollToBottom()
+		}
+		m.statusMessage = ""
+		if msg.err != nil {
+			m.statusMessage = "⚠️ Some steps failed: " + msg.err.Error()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.typing() {
+			break
+		}
+		// Esc cancels a fetch unless its logs are already in the viewer,
+		// which it then returns to.
+		loading := m.session.progress == nil || !m.session.progress.shown
+		if m.session.pending() && (key.Matches(msg, m.keys.Steps.Cancel) || loading && key.Matches(msg, m.keys.Steps.Back)) {
+			m.session.end()
+			m.session.status = "⏹ Cancelling…"
+			return m, nil
+		}
+		k := m.keys.Steps
+		if key.Matches(msg, k.Browse) {
+			if m.browse.client == nil {
+				m.openBrowser(m.session.client, "")
+				return m, m.loadWorkflows()
+			}
+			m.mode = modeBrowse
+			return m, nil
+		}
+		if m.session.wf == nil {
+			if key.Matches(msg, k.Quit) {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, k.Mark):
+			return m, m.session.steps.ToggleMark()
+		case key.Matches(msg, k.Open, k.Containers):
+			var rows []argo.TreeRow
+			if rows = m.session.steps.Marked(); len(rows) == 0 || key.Matches(msg, k.Containers) {
+				row, ok := m.session.steps.Selected()
+				if !ok {
+					return m, nil
+				}
+				if row.Type != "Pod" {
+					return m, m.session.steps.NewStatusMessage("Only Pod nodes have logs")
+				}
+				rows = []argo.TreeRow{row}
+			}
+			if key.Matches(msg, k.Containers) {
+				m.session.status = "📦 Listing containers of " + rows[0].DisplayName + "…"
+				m.session.start()
+				return m, m.loadContainers(rows[0].ID)
+			}
+			sources := make([]stepSource, len(rows))
+			for i, row := range rows {
+				sources[i] = stepSource{nodeID: row.ID, container: "main"}
+			}
+			return m, m.openSources(sources)
+		case key.Matches(msg, k.Summary):
+			m.enterSummary()
+			return m, nil
+		case key.Matches(msg, k.Compare):
+			row, ok := m.session.steps.Selected()
+			switch {
+			case m.session.compareName == "":
+				return m, m.session.steps.NewStatusMessage("Start with --compare <workflow> to compare with another run")
+			case m.session.compareErr != nil:
+				status := m.session.steps.NewStatusMessage("Loading " + m.session.compareName + " again after: " + m.session.compareErr.Error())
+				m.session.compareErr = nil
+				return m, tea.Batch(status, m.loadCompare())
+			case m.session.compare == nil:
+				return m, m.session.steps.NewStatusMessage("Still loading " + m.session.compareName)
+			case !ok || row.Type != "Pod":
+				return m, m.session.steps.NewStatusMessage("Only Pod nodes have logs")
+			}
+			return m, m.loadComparison(row.ID)
+		case key.Matches(msg, k.Back):
+			if len(m.session.sources) > 0 {
+				m.session.status = ""
+				m.mode = modeView
+			}
+			return m, nil
+		case key.Matches(msg, k.Quit):
+			return m, tea.Quit
+		}
+	}
+
+	if m.session.wf == nil {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.session.steps, cmd = m.session.steps.Update(msg)
+	return m, cmd
+}
+
+// updateContainers handles the container picker of one step.
+func (m model) updateContainers(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choice := &m.session.choice
+	k := m.keys.Containers
+	switch {
+	case key.Matches(msg, k.Up):
+		if choice.cursor > 0 {
+			choice.cursor--
+		}
+	case key.Matches(msg, k.Down):
+		if choice.cursor < len(choice.names) {
+			choice.cursor++
+		}
+	case key.Matches(msg, k.Open):
+		names := choice.names
+		if choice.cursor < len(names) {
+			names = names[choice.cursor : choice.cursor+1]
+		}
+		sources := make([]stepSource, len(names))
+		for i, name := range names {
+			sources[i] = stepSource{nodeID: choice.nodeID, container: name}
+		}
+		m.mode = modeSteps
+		return m, m.openSources(sources)
+	case key.Matches(msg, k.Back):
+		m.mode = modeSteps
+	}
+	return m, nil
+}
+
+// enterSteps returns from the viewer to the step picker.
+func (m *model) enterSteps() {
+	m.saveStepView()
+	m.stopStreams()
+	if len(m.session.sources) > 0 {
+		m.session.steps.Select(m.session.sources[0].nodeID)
+	}
+	m.mode = modeSteps
+}
+
+// listColumns is the column layout, led by the step (or pod) and container
+// names when a merged view has several of them. Unless the user picked the
+// layout, the level column fits the longest level, such as CRITICAL.
+func (m model) listColumns() []column {
+	nodes, containers := map[string]bool{}, map[string]bool{}
+	for _, src := range m.shownSources() {
+		nodes[src.nodeID] = true
+		containers[src.container] = true
+	}
+	inLayout := map[string]bool{}
+	for _, col := range m.columns {
+		inLayout[col.Field] = true
+	}
+	var lead []column
+	if len(nodes) > 1 && m.pods.kube != nil && !inLayout["pod"] {
+		lead = append(lead, column{Field: "pod", Width: 24})
+	} else if len(nodes) > 1 && m.pods.kube == nil && !inLayout["step"] {
+		lead = append(lead, column{Field: "step", Width: 16})
+	}
+	if len(containers) > 1 && !inLayout["container"] {
+		lead = append(lead, column{Field: "container", Width: 10})
+	}
+	columns := append(lead, m.columns...)
+	if len(m.cfg.Columns) == 0 {
+		for i, col := range columns {
+			if col.Field == "level" {
+				columns[i].Width = max(col.Width, m.levelWidth())
+			}
+		}
+	}
+	return columns
+}
+
+// stepLegend lists the merged sources with their toggle keys.
+func (m model) stepLegend() string {
+	if !m.merged() {
+		return ""
+	}
+	var parts []string
+	for i, label := range m.sourceLabels() {
+		label = fmt.Sprintf("%d %s", i+1, label)
+		if m.hiddenSteps[m.shownSources()[i].key()] {
+			label = m.theme.Hint.Render(label + " (hidden)")
+		}
+		parts = append(parts, label)
+	}
+	if m.pods.kube != nil {
+		return "Pods: " + strings.Join(parts, " · ")
+	}
+	return "Steps: " + strings.Join(parts, " · ")
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	"logviewer-tui/argo"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// logStream pumps a running container's log lines into a channel the
+// viewer drains between frames.
+type logStream struct {
+	src   stepSource
+	lines chan string
+	err   error // set before lines is closed
+}
+
+// streamLinesMsg and streamEndMsg name their stream, so that those of a
+// stopped stream are told apart from a newer one of the same source.
+type streamLinesMsg struct {
+	stream *logStream
+	src    stepSource
+	lines  []string
+}
+
+type streamEndMsg struct {
+	stream *logStream
+	src    stepSource
+	err    error
+}
+
+// stepFinishedMsg carries the refreshed workflow once a stream has ended
+// and, if the node completed, the container's log artifact.
+type stepFinishedMsg struct {
+	src  stepSource
+	wf   *argo.Workflow
+	logs string
+	err  error
+}
+
+// streamBatch caps the lines handed to the viewer per message.
+const streamBatch = 500
+
+func running(phase string) bool { return phase == "Running" || phase == "Pending" }
+
+func startStream(ctx context.Context, client *argo.Client, wf *argo.Workflow, src stepSource) *logStream {
+	return pumpStream(ctx, src, func(ctx context.Context) (lineStream, error) {
+		return client.StreamNodeLogs(ctx, wf, src.nodeID, src.container)
+	})
+}
+
+// lineStream is a followed container log, from the Argo server or straight
+// from Kubernetes.
+type lineStream interface {
+	Next() (string, error)
+	Close() error
+}
+
+// pumpStream opens a log stream and pumps its lines until it ends or ctx
+// does.
+func pumpStream(ctx context.Context, src stepSource, open func(context.Context) (lineStream, error)) *logStream {
+	s := &logStream{src: src, lines: make(chan string, streamBatch)}
+	go func() {
+		defer close(s.lines)
+		stream, err := open(ctx)
+		if err != nil {
+			s.err = err
+			return
+		}
+		defer stream.Close()
+		for {
+			line, err := stream.Next()
+			if err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					s.err = err
+				}
+				return
+			}
+			select {
+			case s.lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// wait blocks for the next lines, taking whatever else is already buffered.
+func (s *logStream) wait() tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-s.lines
+		if !ok {
+			return streamEndMsg{stream: s, src: s.src, err: s.err}
+		}
+		batch := []string{line}
+		for len(batch) < streamBatch {
+			select {
+			case line, ok := <-s.lines:
+				if !ok {
+					return streamLinesMsg{stream: s, src: s.src, lines: batch}
+				}
+				batch = append(batch, line)
+			default:
+				return streamLinesMsg{stream: s, src: s.src, lines: batch}
+			}
+		}
+		return streamLinesMsg{stream: s, src: s.src, lines: batch}
+	}
+}
+
+// finishStep re-reads the workflow after a stream ended and, once the node
+// is done, fetches the container's artifact to replace the streamed lines.
+func (m model) finishStep(src stepSource) tea.Cmd {
+	client, name := m.session.client, m.session.name
+	return func() tea.Msg {
+		wf, err := client.GetWorkflow(context.Background(), name)
+		if err != nil {
+			return stepFinishedMsg{src: src, err: err}
+		}
+		if running(wf.Status.Nodes[src.nodeID].Phase) {
+			return stepFinishedMsg{src: src, wf: wf}
+		}
+		logs, err := client.GetContainerLogs(context.Background(), wf, src.nodeID, src.container)
+		return stepFinishedMsg{src: src, wf: wf, logs: logs, err: err}
+	}
+}
+
+// streaming reports whether a source of the current selection is live.
+func (m model) streaming() bool {
+	if m.pods.kube != nil {
+		return len(m.pods.streams) > 0
+	}
+	for _, src := range m.session.sources {
+		if _, ok := m.session.streams[src.key()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m model) shows(src stepSource) bool {
+	for _, other := range m.session.sources {
+		if other == src {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSource parses a source's raw logs and tags every entry with it.
+func (m model) parseSource(src stepSource, logs string) []logEntry {
+	parsed := parseLogs(logs, m.fields)
+	for i := range parsed {
+		parsed[i].Step = m.session.wf.Status.Nodes[src.nodeID].DisplayName
+		parsed[i].Container = src.container
+		parsed[i].Source = src.key()
+	}
+	return parsed
+}
+
+// inView reports whether the view with key vk includes src.
+func inView(vk string, src stepSource) bool {
+	for _, k := range strings.Split(vk, ",") {
+		if k == src.key() {
+			return true
+		}
+	}
+	return false
+}
+
+// appendStreamed adds live entries to the source and to every view showing it.
+func (m *model) appendStreamed(src stepSource, entries []logEntry) {
+	m.session.parsed[src.key()] = append(m.session.parsed[src.key()], entries...)
+	for vk, view := range m.session.views {
+		if inView(vk, src) {
+			view.logs = appendSorted(view.logs, entries, strings.Contains(vk, ","))
+			m.session.views[vk] = view
+		}
+	}
+	if m.shows(src) {
+		m.logs = appendSorted(m.logs, entries, m.merged())
+		if m.follow {
+			m.scrollToBottom()
+		}
+	}
+}
+
+func appendSorted(logs, entries []logEntry, merged bool) []logEntry {
+	logs = append(logs, entries...)
+	if merged {
+		sort.SliceStable(logs, func(i, j int) bool {
+			return timestampBefore(logs[i].Timestamp, logs[j].Timestamp)
+		})
+	}
+	return logs
+}
+
+func (m model) updateStream(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case streamLinesMsg:
+		if m.session.streams[msg.src.key()] != msg.stream {
+			return m, nil // stopped
+		}
+		m.appendStreamed(msg.src, m.parseSource(msg.src, strings.Join(msg.lines, "\n")))
+		return m, msg.stream.wait()
+
+	case streamEndMsg:
+		if m.session.streams[msg.src.key()] != msg.stream {
+			return m, nil
+		}
+		delete(m.session.streams, msg.src.key())
+		if msg.err != nil {
+			m.statusMessage = "⚠️ Log stream failed: " + msg.err.Error()
+		}
+		return m, m.finishStep(msg.src)
+
+	case stepFinishedMsg:
+		name := m.sourceLabel(msg.src)
+		var cmd tea.Cmd
+		if msg.wf != nil {
+			m.session.wf = msg.wf
+			cmd = m.session.steps.SetWorkflow(msg.wf)
+		}
+		switch {
+		case msg.err != nil:
+			m.statusMessage = "⚠️ " + name + " finished, keeping streamed logs: " + msg.err.Error()
+			return m, cmd
+		case running(msg.wf.Status.Nodes[msg.src.nodeID].Phase):
+			m.statusMessage = "⚠️ Log stream for " + name + " ended while the step is still running"
+			return m, cmd
+		}
+		parsed := m.parseSource(msg.src, msg.logs)
+		if len(parsed) == 0 {
+			return m, cmd
+		}
+		// The artifact is complete; rebuild every view of the source from it.
+		m.session.parsed[msg.src.key()] = parsed
+		for vk := range m.session.views {
+			if inView(vk, msg.src) {
+				delete(m.session.views, vk)
+			}
+		}
+		if m.shows(msg.src) {
+			var steps [][]logEntry
+			for _, src := range m.session.sources {
+				steps = append(steps, m.session.parsed[src.key()])
+			}
+			m.logs = mergeSteps(steps)
+			if m.follow {
+				m.scrollToBottom()
+			} else {
+				m.cursor = min(m.cursor, max(0, len(m.pagedLogs())-1))
+			}
+		}
+		m.statusMessage = "✅ " + name + " finished"
+		return m, cmd
+	}
+	return m, nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"logviewer-tui/argo"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// summaryRow is a step listed by the summary, whose logs Enter opens.
+type summaryRow struct {
+	nodeID string
+	line   int // index in the summary's lines
+}
+
+// summaryLines renders the workflow summary: metadata, arguments, the
+// failed steps with their messages and the steps' outputs.
+func (m model) summaryLines() ([]string, []summaryRow) {
+	wf := m.session.wf
+	var lines []string
+	var rows []summaryRow
+	add := func(format string, args ...any) { lines = append(lines, fmt.Sprintf(format, args...)) }
+	section := func(title string) { lines = append(lines, "", m.theme.Title.Render(title)) }
+
+	status := []string{argo.PhaseIcon(wf.Status.Phase) + " " + wf.Status.Phase}
+	if !wf.Status.StartedAt.IsZero() {
+		status = append(status, "started "+wf.Status.StartedAt.Local().Format("2006-01-02 15:04:05"), argo.FormatDuration(wf.Duration()))
+	}
+	if wf.Status.Progress != "" {
+		status = append(status, wf.Status.Progress+" done")
+	}
+	if wf.Archived {
+		status = append(status, "archived")
+	}
+	add("%s", strings.Join(status, " · "))
+	add("Namespace %s · UID %s · entrypoint %s", wf.Metadata.Namespace, wf.Metadata.UID, wf.Spec.Entrypoint)
+	if labels := argo.UserLabels(wf.Metadata.Labels); labels != "" {
+		add("Labels %s", labels)
+	}
+	if wf.Status.Message != "" {
+		add("Message: %s", wf.Status.Message)
+	}
+
+	section("Arguments")
+	if len(wf.Spec.Arguments.Parameters) == 0 {
+		add("  %s", m.theme.Hint.Render("(none)"))
+	}
+	for _, p := range wf.Spec.Arguments.Parameters {
+		add("  %s = %s", m.theme.Key.Render(p.Name), oneLine(p.Value))
+	}
+
+	if failed := wf.FailedPods(); len(failed) > 0 {
+		section("Failed steps")
+		for _, node := range failed {
+			rows = append(rows, summaryRow{nodeID: node.ID, line: len(lines)})
+			title := argo.PhaseIcon(node.Phase) + " " + node.DisplayName
+			if node.Outputs != nil && node.Outputs.ExitCode != "" {
+				title += m.theme.Hint.Render(" exit code " + node.Outputs.ExitCode)
+			}
+			add("%s", title)
+			if node.Message != "" {
+				add("    %s", m.theme.level("ERROR").Render(oneLine(node.Message)))
+			}
+		}
+	}
+
+	var withOutputs []argo.Node
+	for _, node := range wf.PodNodes() {
+		if o := node.Outputs; o != nil && (len(o.Parameters) > 0 || o.Result != "") {
+			withOutputs = append(withOutputs, node)
+		}
+	}
+	if len(withOutputs) > 0 {
+		section("Outputs")
+		sort.SliceStable(withOutputs, func(i, j int) bool {
+			return withOutputs[i].StartedAt.Before(withOutputs[j].StartedAt)
+		})
+		for _, node := range withOutputs {
+			rows = append(rows, summaryRow{nodeID: node.ID, line: len(lines)})
+			add("%s %s", argo.PhaseIcon(node.Phase), node.DisplayName)
+			for _, p := range node.Outputs.Parameters {
+				add("    %s = %s", m.theme.Key.Render(p.Name), oneLine(p.Value))
+			}
+			if node.Outputs.Result != "" {
+				add("    %s = %s", m.theme.Key.Render("result"), oneLine(node.Outputs.Result))
+			}
+		}
+	}
+	return lines, rows
+}
+
+// oneLine keeps the first line of a multi-line value.
+func oneLine(s string) string {
+	if first, _, ok := strings.Cut(strings.TrimSpace(s), "\n"); ok {
+		return first + " …"
+	}
+	return strings.TrimSpace(s)
+}
+
+// enterSummary shows the summary with the cursor on the first failed step.
+func (m *model) enterSummary() {
+	m.session.summaryCursor = 0
+	m.mode = modeSummary
+}
+
+func (m model) updateSummary(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	_, rows := m.summaryLines()
+	k := m.keys.Summary
+	switch {
+	case key.Matches(msg, k.Up):
+		if m.session.summaryCursor > 0 {
+			m.session.summaryCursor--
+		}
+	case key.Matches(msg, k.Down):
+		if m.session.summaryCursor < len(rows)-1 {
+			m.session.summaryCursor++
+		}
+	case key.Matches(msg, k.Open):
+		if len(rows) == 0 {
+			return m, nil
+		}
+		nodeID := rows[m.session.summaryCursor].nodeID
+		m.session.steps.Select(nodeID)
+		m.mode = modeSteps
+		return m, m.openSources([]stepSource{{nodeID: nodeID, container: "main"}})
+	case key.Matches(msg, k.Back):
+		m.mode = modeSteps
+	}
+	return m, nil
+}
+
+// viewSummary renders the summary, scrolled to keep the selected step in
+// view.
+func (m model) viewSummary(footer string) string {
+	lines, rows := m.summaryLines()
+	selected := -1
+	if len(rows) > 0 {
+		selected = rows[m.session.summaryCursor].line
+	}
+	for i := range lines {
+		prefix := "  "
+		if i == selected {
+			prefix = "> "
+		}
+		lines[i] = prefix + lines[i]
+	}
+	visible := max(1, m.height-4)
+	start := 0
+	if selected >= visible {
+		start = selected - visible + 1
+	}
+	end := min(len(lines), start+visible)
+	title := m.theme.Title.Render("📋 Summary — " + m.session.wf.Metadata.Name)
+	return title + "\n\n" + strings.Join(lines[start:end], "\n") + "\n\n" + footer
+}
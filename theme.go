@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// theme holds every style the views render with.
+type theme struct {
+	Title  lipgloss.Style
+	Hint   lipgloss.Style
+	Text   lipgloss.Style
+	Border lipgloss.Style
+	Levels map[string]lipgloss.Style
+
+	Key    lipgloss.Style // JSON keys
+	String lipgloss.Style
+	Number lipgloss.Style
+	Bool   lipgloss.Style
+	Null   lipgloss.Style
+}
+
+// themeConfig is a user-defined theme: colors (ANSI index or hex) layered on
+// top of a built-in base theme.
+type themeConfig struct {
+	Base   string            `json:"base,omitempty"`
+	Text   string            `json:"text,omitempty"`
+	Border string            `json:"border,omitempty"`
+	Key    string            `json:"key,omitempty"`
+	String string            `json:"string,omitempty"`
+	Number string            `json:"number,omitempty"`
+	Bool   string            `json:"bool,omitempty"`
+	Null   string            `json:"null,omitempty"`
+	Levels map[string]string `json:"levels,omitempty"`
+}
+
+const defaultThemeName = "dark"
+
+func fg(color string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+}
+
+func baseTheme() theme {
+	return theme{
+		Title: lipgloss.NewStyle().Bold(true).Underline(true),
+		Hint:  lipgloss.NewStyle().Faint(true),
+	}
+}
+
+var builtinThemes = map[string]func() theme{
+	"dark": func() theme {
+		t := baseTheme()
+		t.Text, t.Border = fg("7"), fg("8")
+		t.Key, t.String, t.Number, t.Bool, t.Null = fg("6"), fg("2"), fg("3"), fg("5"), fg("8")
+		t.Levels = map[string]lipgloss.Style{
+			"ERROR": fg("1"), "WARN": fg("3"), "WARNING": fg("3"), "INFO": fg("4"), "DEBUG": fg("8"),
+		}
+		return t
+	},
+	"light": func() theme {
+		t := baseTheme()
+		t.Text, t.Border = fg("0"), fg("250")
+		t.Key, t.String, t.Number, t.Bool, t.Null = fg("25"), fg("28"), fg("130"), fg("90"), fg("244")
+		t.Levels = map[string]lipgloss.Style{
+			"ERROR": fg("124"), "WARN": fg("130"), "WARNING": fg("130"), "INFO": fg("25"), "DEBUG": fg("244"),
+		}
+		return t
+	},
+	"high-contrast": func() theme {
+		t := baseTheme()
+		t.Text, t.Border = fg("15"), fg("15")
+		t.Key, t.String, t.Number, t.Bool, t.Null = fg("14"), fg("10"), fg("11"), fg("13"), fg("15")
+		t.Levels = map[string]lipgloss.Style{
+			"ERROR": fg("9").Bold(true), "WARN": fg("11").Bold(true), "WARNING": fg("11").Bold(true),
+			"INFO": fg("12"), "DEBUG": fg("15"),
+		}
+		return t
+	},
+	"monochrome": func() theme {
+		t := baseTheme()
+		plain := lipgloss.NewStyle()
+		t.Text, t.Border, t.Key, t.String, t.Number, t.Bool, t.Null = plain, plain, plain.Bold(true), plain, plain, plain, plain.Faint(true)
+		t.Levels = map[string]lipgloss.Style{
+			"ERROR": plain.Bold(true), "WARN": plain.Underline(true), "WARNING": plain.Underline(true),
+			"INFO": plain, "DEBUG": plain.Faint(true),
+		}
+		return t
+	},
+}
+
+// resolveTheme picks the theme named by the config, falling back to
+// monochrome whenever NO_COLOR is set (https://no-color.org).
+func resolveTheme(cfg config) theme {
+	name := cfg.Theme
+	if name == "" {
+		name = defaultThemeName
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		name = "monochrome"
+	}
+
+	var t theme
+	if custom, ok := cfg.Themes[name]; ok {
+		t = custom.build()
+	} else {
+		t = builtinThemes[name]()
+	}
+	if name != "monochrome" {
+		for level, color := range cfg.Colors {
+			t.Levels[strings.ToUpper(level)] = fg(color)
+		}
+	}
+	return t
+}
+
+func (c themeConfig) build() theme {
+	base := c.Base
+	if base == "" {
+		base = defaultThemeName
+	}
+	t := builtinThemes[base]()
+	set := func(dst *lipgloss.Style, color string) {
+		if color != "" {
+			*dst = fg(color)
+		}
+	}
+	set(&t.Text, c.Text)
+	set(&t.Border, c.Border)
+	set(&t.Key, c.Key)
+	set(&t.String, c.String)
+	set(&t.Number, c.Number)
+	set(&t.Bool, c.Bool)
+	set(&t.Null, c.Null)
+	for level, color := range c.Levels {
+		t.Levels[strings.ToUpper(level)] = fg(color)
+	}
+	return t
+}
+
+func validateThemes(cfg config) []error {
+	var errs []error
+	for name, custom := range cfg.Themes {
+		if _, ok := builtinThemes[name]; ok {
+			errs = append(errs, fmt.Errorf("themes.%s: shadows a built-in theme", name))
+		}
+		if _, ok := builtinThemes[custom.Base]; custom.Base != "" && !ok {
+			errs = append(errs, fmt.Errorf("themes.%s.base: unknown built-in theme %q", name, custom.Base))
+		}
+	}
+	if cfg.Theme != "" {
+		_, builtin := builtinThemes[cfg.Theme]
+		_, custom := cfg.Themes[cfg.Theme]
+		if !builtin && !custom {
+			errs = append(errs, fmt.Errorf("theme: unknown theme %q", cfg.Theme))
+		}
+	}
+	return errs
+}
+
+func (t theme) level(level string) lipgloss.Style {
+	if style, ok := t.Levels[strings.ToUpper(level)]; ok {
+		return style
+	}
+	return lipgloss.NewStyle()
+}
+
+// bar renders a progress bar width cells wide, the done part in the key
+// color and the rest in the border color.
+func (t theme) bar(done float64, width int) string {
+	filled := min(max(int(done*float64(width)+0.5), 0), width)
+	return t.Key.Render(strings.Repeat("█", filled)) + t.Border.Render(strings.Repeat("░", width-filled))
+}
+
+func (t theme) renderValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return t.String.Render(fmt.Sprintf(`"%s"`, val))
+	case float64, int:
+		return t.Number.Render(fmt.Sprintf("%v", val))
+	case bool:
+		return t.Bool.Render(fmt.Sprintf("%v", val))
+	case nil:
+		return t.Null.Render("null")
+	default:
+		encoded, _ := json.Marshal(val)
+		return t.String.Render(string(encoded))
+	}
+}
+
+// renderJSONLines renders one `"key": value` line per field, sorted by key
+// and wrapped at width.
+func (t theme) renderJSONLines(data map[string]interface{}, indent string, width int) []string {
+	var keys []string
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		key := t.Key.Render(fmt.Sprintf(`"%s"`, k))
+		line := fmt.Sprintf("%s%s: %s", indent, key, t.renderValue(data[k]))
+		if width > 0 {
+			line = lipgloss.NewStyle().Width(width).Render(line)
+		}
+		lines = append(lines, strings.Split(line, "\n")...)
+	}
+	return lines
+}
+
+// renderJSON renders the details shown under an expanded list entry.
+func (t theme) renderJSON(data map[string]interface{}) string {
+	var b strings.Builder
+	for _, line := range t.renderJSONLines(data, "    ", 0) {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// newHelp returns a help bubble styled after t.
+func newHelp(t theme) help.Model {
+	h := help.New()
+	h.Styles.ShortKey = t.Key
+	h.Styles.FullKey = t.Key
+	h.Styles.ShortDesc = t.Hint
+	h.Styles.FullDesc = t.Hint
+	h.Styles.ShortSeparator = t.Hint
+	h.Styles.FullSeparator = t.Hint
+	h.Styles.Ellipsis = t.Hint
+	return h
+}
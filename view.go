@@ -1,9 +1,7 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -17,10 +15,59 @@ func max(a, b int) int {
 }
 
 func (m model) View() string {
+	if m.showHelp {
+		group := m.keys.group(m.mode)
+		title := m.theme.Title.Render("⌨️  Keys — " + group.title)
+		return title + "\n\n" + m.help.FullHelpView(group.FullHelp()) + "\n\n" + m.theme.Hint.Render("(any key to close)")
+	}
+
+	footer := m.help.ShortHelpView(m.keys.group(m.mode).ShortHelp())
 	switch m.mode {
+	case modeSteps:
+		if m.session.wf == nil {
+			return m.session.status + "\n\n" + footer
+		}
+		status := ""
+		if m.session.status != "" {
+			status = m.session.status + "\n"
+		} else if wf := m.session.wf; wf.Status.Message != "" && (wf.Status.Phase == "Failed" || wf.Status.Phase == "Error") {
+			status = m.theme.level("ERROR").Render("✖ "+oneLine(wf.Status.Message)) +
+				m.theme.Hint.Render(" ("+m.keys.Steps.Summary.Help().Key+" for the summary)") + "\n"
+		}
+		if p := m.session.progress; p != nil {
+			status += m.progressLine(p) + "\n"
+		}
+		return m.session.steps.View() + "\n" + status + footer
+
+	case modeSummary:
+		return m.viewSummary(footer)
+
+	case modeCompare:
+		return m.viewCompare(footer)
+
+	case modePods:
+		return m.viewPods(footer)
+
+	case modeBrowse, modeBrowseEdit:
+		return m.viewBrowse(footer)
+
+	case modeContainers:
+		choice := m.session.choice
+		title := m.theme.Title.Render("📦 Containers — " + m.session.wf.Status.Nodes[choice.nodeID].DisplayName)
+
+		rows := append(append([]string{}, choice.names...), "all containers, merged")
+		var b strings.Builder
+		for i, name := range rows {
+			prefix := "  "
+			if i == choice.cursor {
+				prefix = "> "
+			}
+			b.WriteString(prefix + name + "\n")
+		}
+		return title + "\n\n" + b.String() + "\n" + footer
+
 	case modeFullDetail:
-		title := lipgloss.NewStyle().Bold(true).Underline(true).Render("🔍 Full JSON Detail View")
-		footer := lipgloss.NewStyle().Faint(true).Render("(↑↓ scroll, q/esc back)")
+		title := m.theme.Title.Render("🔍 Full JSON Detail View")
 
 		// Compute visible lines
 		start := m.detailOffset
@@ -32,134 +79,152 @@ func (m model) View() string {
 
 		return fmt.Sprintf("%s\n\n%s\n\n%s", title, content, footer)
 
+	case modeColumns:
+		title := m.theme.Title.Render("🧱 Columns")
+
+		var b strings.Builder
+		start := 0
+		if visible := max(1, m.height-2); m.pickerCursor >= visible {
+			start = m.pickerCursor - visible + 1
+		}
+		for i := start; i < len(m.pickerItems) && i < start+max(1, m.height-2); i++ {
+			it := m.pickerItems[i]
+			prefix := "  "
+			if i == m.pickerCursor {
+				prefix = "> "
+			}
+			check := "[ ]"
+			if it.Enabled {
+				check = "[x]"
+			}
+			width := "fill"
+			if it.Width > 0 {
+				width = fmt.Sprintf("%d", it.Width)
+			}
+			line := fmt.Sprintf("%s%s %-40s %s", prefix, check, it.Field, width)
+			if !it.Enabled {
+				line = m.theme.Hint.Render(line)
+			}
+			b.WriteString(line + "\n")
+		}
+		status := ""
+		if m.statusMessage != "" {
+			status = "\n" + m.statusMessage
+		}
+		return title + "\n\n" + b.String() + "\n" + footer + status
+
 	case modeRegexFilter:
-		title := lipgloss.NewStyle().Bold(true).Underline(true).Render("🧹 Exclude Logs by Regex")
-		helper := lipgloss.NewStyle().Faint(true).Render("(Enter = apply filter, Esc = cancel)")
-		return title + "\n\n" + m.regexInput.View() + "\n" + helper
+		title := m.theme.Title.Render("🧹 Exclude Logs by Regex")
+		return title + "\n\n" + m.regexInput.View() + "\n" + footer
 	case modePaste:
-		title := lipgloss.NewStyle().Bold(true).Underline(true).Render("📋 Paste Mode")
+		title := m.theme.Title.Render("📋 Paste Mode")
 
-		hint := lipgloss.NewStyle().Faint(true).Render(`
+		hint := m.theme.Hint.Render(fmt.Sprintf(`
 Instructions:
 • Paste logs directly into the input area below (limited to ~99 lines).
 • For larger log files, drag and drop a .log or .txt or .json file into the terminal to load automatically.
-• Press Enter to continue or Esc to cancel.
-`)
-		return title + "\n\n" + m.textarea.View() + "\n" + hint + "\n\n(Enter = done, ctrl-z = clear, Esc = quit)"
+• Press %s to continue or %s to cancel.
+`, m.keys.Paste.Done.Help().Key, m.keys.Paste.Quit.Help().Key))
+		return title + "\n\n" + m.textarea.View() + "\n" + hint + "\n\n" + footer
 	case modeView:
 		var b strings.Builder
 
-		filtered := m.pagedLogs()
-		// Adjust these for your layout preference
-		const messageStartColumn = 36
-
-		for i, log := range filtered {
-			globalIndex := m.offset + i
-			prefix := "  "
-			if i == m.cursor {
-				prefix = "> "
-			}
-			indicator := "  "
-			if len(log.Details) > 0 {
-				if m.logs[globalIndex].Expanded {
-					indicator = "⏷ " // down arrow = expanded
-				} else {
-					indicator = "⏵ " // right arrow = collapsed
-				}
-			}
-
-			level := strings.ToUpper(log.Level)
-			levelStyle := levelColor(level)
-			white := lipgloss.NewStyle().Foreground(lipgloss.Color("7"))
-
-			// Format core line parts
-			ts := fmt.Sprintf("[%s]", log.Timestamp)
-			lv := fmt.Sprintf("[%s]", level)
-			header := ts + lv
-
-			// Compute padding so message always starts at column N
-			headerWidth := lipgloss.Width(prefix + header)
-			spacing := strings.Repeat(" ", max(0, messageStartColumn-headerWidth))
-			line := indicator + header + spacing + log.Message
-
-			// Render based on level
-			switch level {
-			case "ERROR", "WARN", "WARNING":
-				b.WriteString(prefix + levelStyle.Render(line) + "\n")
-			default:
-				b.WriteString(prefix + white.Render(indicator+ts) + levelStyle.Render(lv) + white.Render(spacing+log.Message) + "\n")
-			}
+		list := m.renderList(m.listWidth())
+		switch m.split {
+		case splitSideBySide:
+			listPane := lipgloss.NewStyle().Width(m.listWidth()).Render(list)
+			b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, listPane, m.renderPreview()) + "\n")
+		case splitTopBottom:
+			listPane := lipgloss.NewStyle().Height(m.listHeight()).Render(list)
+			b.WriteString(lipgloss.JoinVertical(lipgloss.Left, listPane, m.renderPreview()) + "\n")
+		default:
+			b.WriteString(list)
+		}
 
-			if log.Expanded && len(log.Details) > 0 {
-				b.WriteString(renderStyledJSON(log.Details) + "\n")
+		name := "📊 Log Viewer"
+		if m.pods.kube != nil && len(m.pods.sources) > 0 {
+			name += " — " + strings.Join(m.sourceLabels(), ", ")
+		} else if steps := m.sourceLabels(); len(steps) > 0 {
+			name += " — " + m.session.name + " / " + strings.Join(steps, ", ")
+		}
+		if m.streaming() {
+			name += " ● live"
+			if m.follow {
+				name += " (following)"
 			}
 		}
-
-		if len(filtered) == 0 {
-			b.WriteString(lipgloss.NewStyle().Faint(true).Render("No logs match the selected filter.\n"))
+		title := m.theme.Title.Render(name)
+		h := m.help
+		h.Width = max(0, h.Width-lipgloss.Width(title)-1)
+		b.WriteString("\n" + title + " " + h.ShortHelpView(m.keys.group(m.mode).ShortHelp()) + "\n")
+		if legend := m.stepLegend(); legend != "" {
+			b.WriteString(legend + "\n")
+		}
+		if p := m.session.progress; p != nil && p.shown {
+			b.WriteString(m.progressLine(p) + "\n")
+		}
+		if m.statusMessage != "" {
+			b.WriteString(m.statusMessage + "\n")
 		}
-
-		title := lipgloss.NewStyle().Bold(true).Underline(true).Render("📊 Log Viewer")
-		helper := lipgloss.NewStyle().Faint(true).Render(
-			title + "(q quit, z back, ↑↓ scroll, ⏎/space expand, e/w/i/d/a filter, r regex exclude, v view full JSON)",
-		)
-		b.WriteString("\n" + helper + "\n")
 
 		return b.String()
 	}
 	return ""
 }
 
-func renderStyledJSON(data map[string]interface{}) string {
+// renderList renders the current page of log lines, width columns wide.
+func (m model) renderList(width int) string {
 	var b strings.Builder
-	cyan := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))    // keys
-	green := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))   // strings
-	yellow := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))  // numbers
-	magenta := lipgloss.NewStyle().Foreground(lipgloss.Color("5")) // booleans
-	gray := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))    // null
-
-	renderValue := func(v interface{}) string {
-		switch val := v.(type) {
-		case string:
-			return green.Render(fmt.Sprintf(`"%s"`, val))
-		case float64, int:
-			return yellow.Render(fmt.Sprintf("%v", val))
-		case bool:
-			return magenta.Render(fmt.Sprintf("%v", val))
-		case nil:
-			return gray.Render("null")
+	filtered := m.pagedLogs()
+
+	for i, log := range filtered {
+		prefix := "  "
+		if i == m.cursor {
+			prefix = "> "
+		}
+		indicator := "  "
+		if len(log.Details) > 0 {
+			if log.Expanded {
+				indicator = "⏷ " // down arrow = expanded
+			} else {
+				indicator = "⏵ " // right arrow = collapsed
+			}
+		}
+
+		level := strings.ToUpper(log.Level)
+		levelStyle := m.theme.level(level)
+		white := m.theme.Text
+
+		avail := 0
+		if width > 0 {
+			avail = max(1, width-lipgloss.Width(prefix+indicator))
+		}
+		columns := m.listColumns()
+		cells := renderColumns(log, columns, avail)
+
+		// Render based on level
+		switch level {
+		case "ERROR", "WARN", "WARNING":
+			b.WriteString(prefix + levelStyle.Render(indicator+strings.Join(cells, "")) + "\n")
 		default:
-			encoded, _ := json.Marshal(val)
-			return green.Render(string(encoded))
+			line := white.Render(indicator)
+			for j, cell := range cells {
+				if columns[j].Field == "level" {
+					line += levelStyle.Render(cell)
+				} else {
+					line += white.Render(cell)
+				}
+			}
+			b.WriteString(prefix + line + "\n")
 		}
-	}
 
-	var keys []string
-	for k := range data {
-		keys = append(keys, k)
+		if log.Expanded && len(log.Details) > 0 {
+			b.WriteString(m.theme.renderJSON(log.Details) + "\n")
+		}
 	}
-	sort.Strings(keys)
 
-	for _, k := range keys {
-		key := cyan.Render(fmt.Sprintf(`"%s"`, k))
-		val := renderValue(data[k])
-		b.WriteString(fmt.Sprintf("    %s: %s\n", key, val))
+	if len(filtered) == 0 {
+		b.WriteString(m.theme.Hint.Render("No logs match the selected filter.\n"))
 	}
-
 	return b.String()
 }
-
-func levelColor(level string) lipgloss.Style {
-	switch strings.ToUpper(level) {
-	case "ERROR":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Red
-	case "WARN", "WARNING":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("3")) // Yellow
-	case "INFO":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("4")) // Blue
-	case "DEBUG":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("8")) // Gray
-	default:
-		return lipgloss.NewStyle()
-	}
-}